@@ -8,11 +8,14 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/spf13/pflag"
 	openapinamer "k8s.io/apiserver/pkg/endpoints/openapi"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/component-base/tracing"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/apiserver"
 	basecmd "sigs.k8s.io/custom-metrics-apiserver/pkg/cmd"
@@ -36,10 +39,21 @@ type Options struct {
 	Args                    []string
 	ExtraFlags              *pflag.FlagSet
 	ExternalMetricsProvider provider.ExternalMetricsProvider
+	// Handlers are extra HTTP handlers served by the secure server under given paths. Requests to them
+	// are subject to delegated authentication and authorization as non-resource URLs.
+	Handlers map[string]http.Handler
+	// ReadyzChecks are extra checks which must pass before the adapter reports being ready on /readyz.
+	ReadyzChecks []healthz.HealthChecker
+	// TracerProvider records spans of requests served by the adapter. If nil, no spans are recorded.
+	TracerProvider tracing.TracerProvider
 }
 
 type adapter struct {
 	basecmd.AdapterBase
+
+	handlers       map[string]http.Handler
+	readyzChecks   []healthz.HealthChecker
+	tracerProvider tracing.TracerProvider
 }
 
 // Adapter represents adapter functionality.
@@ -73,11 +87,48 @@ func NewAdapter(options Options) (Adapter, error) {
 		return nil, fmt.Errorf("external metrics provider must be configured")
 	}
 
+	for path := range options.Handlers {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("handler path %q must start with %q", path, "/")
+		}
+	}
+
 	a.WithExternalMetrics(options.ExternalMetricsProvider)
+	a.handlers = options.Handlers
+	a.readyzChecks = options.ReadyzChecks
+	a.tracerProvider = options.TracerProvider
 
 	return a, nil
 }
 
+// Run runs the adapter with configured extra handlers and checks until given context is canceled.
+func (a *adapter) Run(ctx context.Context) error {
+	if a.tracerProvider != nil {
+		config, err := a.Config()
+		if err != nil {
+			return fmt.Errorf("creating server config: %w", err)
+		}
+
+		// Spans of the providers are then children of the span of the request to the API server.
+		config.GenericConfig.TracerProvider = a.tracerProvider
+	}
+
+	server, err := a.Server()
+	if err != nil {
+		return fmt.Errorf("creating server: %w", err)
+	}
+
+	for path, handler := range a.handlers {
+		server.GenericAPIServer.Handler.NonGoRestfulMux.Handle(path, handler)
+	}
+
+	if err := server.GenericAPIServer.AddReadyzChecks(a.readyzChecks...); err != nil {
+		return fmt.Errorf("adding readyz checks: %w", err)
+	}
+
+	return server.GenericAPIServer.PrepareRun().RunWithContext(ctx) //nolint:wrapcheck // Same as AdapterBase.Run.
+}
+
 // ParseFlags parses given arguments as custom custom-metrics-apiserver into given adapter base.
 //
 // It also allows specifying extra flags if one wants to add extra flags to API server built on top
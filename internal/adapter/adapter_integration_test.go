@@ -9,16 +9,22 @@ package adapter_test
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"k8s.io/apiserver/pkg/server/healthz"
+
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/adapter"
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
 )
 
 const (
+	testHandlerPath     = "/debug/test"
+	testReadyzCheckName = "test-check"
+
 	// Each of integration tests should not take more than 10 seconds to complete
 	// under normal circumstances. This should make detecting bugs faster.
 	testMaxExecutionTime = 10 * time.Second
@@ -68,6 +74,22 @@ func Test_Adapter_responds_to(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("configured_extra_handler", func(t *testing.T) {
+		t.Parallel()
+
+		url := fmt.Sprintf("%s%s", testEnv.BaseURL, testHandlerPath)
+
+		testutil.CheckStatusCodeOK(testEnv.Context, t, testEnv.HTTPClient, url)
+	})
+
+	t.Run("readiness_request_with_configured_extra_check", func(t *testing.T) {
+		t.Parallel()
+
+		url := fmt.Sprintf("%s/readyz/%s", testEnv.BaseURL, testReadyzCheckName)
+
+		testutil.CheckStatusCodeOK(testEnv.Context, t, testEnv.HTTPClient, url)
+	})
 }
 
 func Test_Adapter_listens_on_non_privileged_port_by_default(t *testing.T) {
@@ -117,6 +139,14 @@ func runAdapter(t *testing.T, testEnv *testutil.TestEnv) {
 	options := adapter.Options{
 		ExternalMetricsProvider: &mock.Provider{},
 		Args:                    testEnv.Flags,
+		Handlers: map[string]http.Handler{
+			testHandlerPath: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+		},
+		ReadyzChecks: []healthz.HealthChecker{
+			healthz.NamedCheck(testReadyzCheckName, func(*http.Request) error { return nil }),
+		},
 	}
 
 	adapter, err := adapter.NewAdapter(options)
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
 	"testing"
@@ -116,6 +117,17 @@ func Test_Creating_adapter(t *testing.T) { //nolint:funlen // Just a lot of test
 				t.Fatalf("Expected error")
 			}
 		})
+
+		t.Run("handler_path_is_not_absolute", func(t *testing.T) {
+			t.Parallel()
+
+			options := testOptions()
+			options.Handlers = map[string]http.Handler{"debug": http.NotFoundHandler()}
+
+			if _, err := adapter.NewAdapter(options); err == nil {
+				t.Fatalf("Expected error")
+			}
+		})
 	})
 }
 
@@ -0,0 +1,28 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cacheresult passes the result of the cache lookup of a request to the providers wrapping the cache,
+// so they can report it without depending on the cache provider and the cache provider does not depend on them.
+package cacheresult
+
+import "context"
+
+type contextKey struct{}
+
+// Recorder holds the result of the cache lookup of a single request.
+type Recorder struct {
+	// Result is the result of the cache lookup, empty when the cache is disabled.
+	Result string
+}
+
+// NewContext returns context recording the result of the cache lookup of the request to the given recorder.
+func NewContext(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, recorder)
+}
+
+// Record records the result of the cache lookup of the request, if the given context holds a recorder.
+func Record(ctx context.Context, result string) {
+	if recorder, ok := ctx.Value(contextKey{}).(*Recorder); ok {
+		recorder.Result = result
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logkeys defines keys of structured log entries, so the same field is logged under the same key
+// across the adapter and can be queried reliably.
+package logkeys
+
+const (
+	// Metric is the name of the external metric.
+	Metric = "metric"
+	// Selector is the label selector of the request.
+	Selector = "selector"
+	// Namespace is the namespace of the request.
+	Namespace = "namespace"
+	// AccountID is the New Relic account the query is executed for.
+	AccountID = "accountID"
+	// Query is the NRQL query.
+	Query = "query"
+	// Duration is the time it took to complete the operation.
+	Duration = "duration"
+	// CacheResult is the result of the cache lookup: hit, miss or negative_hit.
+	CacheResult = "cacheResult"
+	// ErrorClass is the class of the query error.
+	ErrorClass = "errorClass"
+	// Key is the key of the cache entry.
+	Key = "key"
+	// Source is the source of the served value.
+	Source = "source"
+	// Message is a message returned by NRDB with the query result.
+	Message = "nrdbMessage"
+	// Error is the error of the operation, if any.
+	Error = "err"
+	// Count is the number of items the operation handled, e.g. purged cache entries.
+	Count = "count"
+	// Total is the number of items the operation was supposed to handle.
+	Total = "total"
+	// Delay is the time the operation is postponed by, e.g. due to rate limiting or backoff.
+	Delay = "delay"
+	// Interval is the interval enforced between queries.
+	Interval = "interval"
+	// Limit is the configured limit reached by the operation.
+	Limit = "limit"
+	// MaxConcurrentQueries is the maximum number of queries executed at the same time.
+	MaxConcurrentQueries = "maxConcurrentQueries"
+	// FailureThreshold is the number of consecutive failures opening a circuit breaker.
+	FailureThreshold = "failureThreshold"
+	// MaxRetries is the maximum number of retries of a query.
+	MaxRetries = "maxRetries"
+	// MaxRequests is the maximum number of HTTP requests sent for a single query, including retries.
+	MaxRequests = "maxRequestsPerQuery"
+	// Scope is the scope of the circuit breaker: connection or metric.
+	Scope = "scope"
+	// State is the current state, e.g. of a circuit breaker.
+	State = "state"
+	// PreviousState is the state before the change, e.g. of a circuit breaker.
+	PreviousState = "previousState"
+	// Failures is the number of consecutive failures.
+	Failures = "failures"
+	// Fallback is the index of the fallback of the metric.
+	Fallback = "fallback"
+	// ConfigMap is the namespaced name of the ConfigMap.
+	ConfigMap = "configMap"
+	// Holder is the identity of the replica holding a lock.
+	Holder = "holder"
+	// File is the path of the file.
+	File = "file"
+	// EventType is the type of events reported to New Relic.
+	EventType = "eventType"
+)
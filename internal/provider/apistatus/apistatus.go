@@ -0,0 +1,55 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package apistatus preserves Kubernetes API status of errors wrapped by the providers.
+//
+// The API server only recognizes the status of the error it receives directly, so wrapping
+// an error with API status, e.g. TooManyRequests, would otherwise turn it into an internal error.
+package apistatus
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type statusError struct {
+	err    error
+	status metav1.Status
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// Status implements apierrors.APIStatus, reporting the status of the wrapped error with the full error message.
+func (e *statusError) Status() metav1.Status {
+	status := e.status
+	status.Message = e.err.Error()
+
+	return status
+}
+
+// Preserve returns an error reporting the API status of the error wrapped by the given error, if there is any.
+// Otherwise the given error is returned unchanged.
+func Preserve(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(apierrors.APIStatus); ok { //nolint:errorlint // Only the outermost error is checked by the API server.
+		return err
+	}
+
+	var wrapped apierrors.APIStatus
+	if !errors.As(err, &wrapped) {
+		return err
+	}
+
+	return &statusError{err: err, status: wrapped.Status()}
+}
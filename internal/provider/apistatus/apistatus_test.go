@@ -0,0 +1,58 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apistatus_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/apistatus"
+)
+
+func Test_Preserving_API_status(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports_status_of_wrapped_error_with_full_message", func(t *testing.T) {
+		t.Parallel()
+
+		err := apistatus.Preserve(fmt.Errorf("getting value: %w", apierrors.NewTooManyRequests("slow down", 5)))
+
+		status := responsewriters.ErrorToAPIStatus(err)
+
+		if status.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected status code %d, got %d", http.StatusTooManyRequests, status.Code)
+		}
+
+		if expected := "getting value: slow down"; status.Message != expected {
+			t.Fatalf("Expected message %q, got %q", expected, status.Message)
+		}
+
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected error to still be recognized as too many requests")
+		}
+	})
+
+	t.Run("returns_error_without_status_unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("getting value: %w", errors.New("failed"))
+
+		if preserved := apistatus.Preserve(err); preserved != err { //nolint:errorlint // Identity is checked.
+			t.Fatalf("Expected error to be returned unchanged, got %v", preserved)
+		}
+	})
+
+	t.Run("returns_nil_for_nil_error", func(t *testing.T) {
+		t.Parallel()
+
+		if err := apistatus.Preserve(nil); err != nil {
+			t.Fatalf("Expected nil, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package audit implements the external provider interface recording each request for an external metric
+// served by the encapsulated provider, together with the identity of the requester and the returned value.
+//
+// Events are written as JSON objects, one per line, so scaling decisions can be analyzed after an incident.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/cacheresult"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+// StdoutPath makes audit events to be written to the standard output instead of a file.
+const StdoutPath = "-"
+
+// Event is a record of a single request for an external metric.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	// User and Groups identify the requester, e.g. the service account of the HPA controller.
+	User      string   `json:"user,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+	Namespace string   `json:"namespace"`
+	Metric    string   `json:"metric"`
+	Selector  string   `json:"selector"`
+	// Value is the returned value, empty when the request failed.
+	Value string `json:"value,omitempty"`
+	// Labels are labels of the returned value, e.g. the source of a value served by a fallback.
+	Labels map[string]string `json:"labels,omitempty"`
+	// CacheResult is the result of the cache lookup, empty when the cache is disabled.
+	CacheResult     string  `json:"cacheResult,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// ProviderOptions holds the configuration of the audit provider.
+type ProviderOptions struct {
+	ExternalProvider provider.ExternalMetricsProvider
+	// Writer receives the audit events.
+	Writer io.Writer
+}
+
+type auditProvider struct {
+	externalProvider provider.ExternalMetricsProvider
+
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewAuditProvider is the constructor for the audit provider.
+func NewAuditProvider(options ProviderOptions) (provider.ExternalMetricsProvider, error) {
+	if options.ExternalProvider == nil {
+		return nil, fmt.Errorf("external provider must be configured")
+	}
+
+	if options.Writer == nil {
+		return nil, fmt.Errorf("writer must be configured")
+	}
+
+	return &auditProvider{
+		externalProvider: options.ExternalProvider,
+		encoder:          json.NewEncoder(options.Writer),
+	}, nil
+}
+
+// GetExternalMetric returns the requested metric and records the request.
+func (p *auditProvider) GetExternalMetric(ctx context.Context, namespace string, match labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+	event := &Event{
+		Timestamp: time.Now(),
+		Namespace: namespace,
+		Metric:    info.Metric,
+	}
+
+	if match != nil {
+		event.Selector = match.String()
+	}
+
+	if user, ok := request.UserFrom(ctx); ok {
+		event.User = user.GetName()
+		event.Groups = user.GetGroups()
+	}
+
+	cacheResult := &cacheresult.Recorder{}
+
+	v, err := p.externalProvider.GetExternalMetric(cacheresult.NewContext(ctx, cacheResult), namespace, match, info)
+
+	event.DurationSeconds = time.Since(event.Timestamp).Seconds()
+	event.CacheResult = cacheResult.Result
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	if v != nil && len(v.Items) > 0 {
+		event.Value = v.Items[0].Value.String()
+		event.Labels = v.Items[0].MetricLabels
+	}
+
+	p.record(event)
+
+	return v, err //nolint:wrapcheck // Errors of the external provider carry the API status.
+}
+
+// ListAllExternalMetrics returns the list of external metrics supported by the encapsulated provider.
+func (p *auditProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
+	return p.externalProvider.ListAllExternalMetrics()
+}
+
+func (p *auditProvider) record(event *Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.encoder.Encode(event); err != nil {
+		klog.ErrorS(err, "Writing audit event", logkeys.Metric, event.Metric, logkeys.Selector, event.Selector)
+	}
+}
+
+// WriterOptions holds the configuration of the file audit events are written to.
+type WriterOptions struct {
+	// Path of the file. StdoutPath makes events to be written to the standard output.
+	Path string
+	// MaxSizeMegabytes is the size of the file after which it is rotated. Defaults to 100 megabytes.
+	MaxSizeMegabytes int
+	// MaxBackups is the number of rotated files to retain. Zero retains all of them.
+	MaxBackups int
+	// MaxAgeDays is the number of days to retain rotated files for. Zero retains them regardless of age.
+	MaxAgeDays int
+	// Compress compresses rotated files using gzip.
+	Compress bool
+}
+
+// NewWriter returns writer of audit events to the configured file, which is rotated once it grows over
+// the configured size.
+func NewWriter(options WriterOptions) (io.WriteCloser, error) {
+	switch options.Path {
+	case "":
+		return nil, fmt.Errorf("path must be configured")
+	case StdoutPath:
+		return nopCloser{Writer: os.Stdout}, nil
+	}
+
+	return &lumberjack.Logger{
+		Filename:   options.Path,
+		MaxSize:    options.MaxSizeMegabytes,
+		MaxBackups: options.MaxBackups,
+		MaxAge:     options.MaxAgeDays,
+		Compress:   options.Compress,
+	}, nil
+}
+
+// nopCloser prevents closing the standard output.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/cacheresult"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/audit"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const hpaServiceAccount = "system:serviceaccount:kube-system:horizontal-pod-autoscaler"
+
+//nolint:funlen // Just a large test suite.
+func Test_Getting_external_metric_records_audit_event(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with_requester_identity_returned_value_and_cache_result", func(t *testing.T) {
+		t.Parallel()
+
+		mockProvider := &mock.Provider{
+			GetExternalMetricFunc: func(ctx context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+				cacheresult.Record(ctx, "hit")
+
+				return (&mock.Provider{}).GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{})
+			},
+		}
+
+		buffer := &bytes.Buffer{}
+
+		p := testAuditProvider(t, mockProvider, buffer)
+
+		ctx := request.WithUser(testutil.ContextWithDeadline(t), &user.DefaultInfo{
+			Name:   hpaServiceAccount,
+			Groups: []string{"system:serviceaccounts"},
+		})
+
+		sl, err := labels.Parse("app=foo")
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		if _, err := p.GetExternalMetric(ctx, "default", sl, provider.ExternalMetricInfo{Metric: "mock_metric"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedEvent := audit.Event{
+			User:        hpaServiceAccount,
+			Groups:      []string{"system:serviceaccounts"},
+			Namespace:   "default",
+			Metric:      "mock_metric",
+			Selector:    "app=foo",
+			Value:       "1",
+			Labels:      map[string]string{"foo": "bar"},
+			CacheResult: "hit",
+		}
+
+		if diff := cmp.Diff(expectedEvent, recordedEvent(t, buffer), ignoreTiming()); diff != "" {
+			t.Fatalf("Unexpected audit event (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("with_error_when_getting_metric_fails", func(t *testing.T) {
+		t.Parallel()
+
+		expectedErr := apierrors.NewServiceUnavailable("backend unavailable")
+
+		mockProvider := &mock.Provider{
+			GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+				return nil, expectedErr
+			},
+		}
+
+		buffer := &bytes.Buffer{}
+
+		p := testAuditProvider(t, mockProvider, buffer)
+
+		ctx := testutil.ContextWithDeadline(t)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: "mock_metric"}); err != expectedErr { //nolint:errorlint,lll // Error must be returned as it is.
+			t.Fatalf("Expected error %v, got %v", expectedErr, err)
+		}
+
+		expectedEvent := audit.Event{
+			Metric: "mock_metric",
+			Error:  expectedErr.Error(),
+		}
+
+		if diff := cmp.Diff(expectedEvent, recordedEvent(t, buffer), ignoreTiming()); diff != "" {
+			t.Fatalf("Unexpected audit event (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func Test_Creating_audit_provider_fails_when(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]audit.ProviderOptions{
+		"external_provider_is_not_configured": {Writer: &bytes.Buffer{}},
+		"writer_is_not_configured":            {ExternalProvider: &mock.Provider{}},
+	}
+
+	for testCaseName, options := range cases {
+		options := options
+
+		t.Run(testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := audit.NewAuditProvider(options); err == nil {
+				t.Fatalf("Expected error creating the provider")
+			}
+		})
+	}
+}
+
+func Test_Audit_writer_writes_events_to_configured_file(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	writer, err := audit.NewWriter(audit.WriterOptions{Path: path})
+	if err != nil {
+		t.Fatalf("Unexpected error creating writer: %v", err)
+	}
+
+	p := testAuditProvider(t, &mock.Provider{}, writer)
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: "mock_metric"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Unexpected error closing writer: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading audit log: %v", err)
+	}
+
+	if lines := bytes.Count(content, []byte("\n")); lines != 2 {
+		t.Fatalf("Expected 2 events in audit log, got %d:\n%s", lines, content)
+	}
+}
+
+func testAuditProvider(t *testing.T, p provider.ExternalMetricsProvider, w io.Writer) provider.ExternalMetricsProvider {
+	t.Helper()
+
+	auditProvider, err := audit.NewAuditProvider(audit.ProviderOptions{ExternalProvider: p, Writer: w})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	return auditProvider
+}
+
+// recordedEvent returns the single event written to the buffer.
+func recordedEvent(t *testing.T, buffer *bytes.Buffer) audit.Event {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("Expected exactly 1 audit event, got %d: %s", len(lines), buffer.String())
+	}
+
+	event := audit.Event{}
+	if err := json.Unmarshal(lines[0], &event); err != nil {
+		t.Fatalf("Decoding audit event: %v", err)
+	}
+
+	if event.Timestamp.IsZero() || event.DurationSeconds < 0 {
+		t.Errorf("Expected audit event to have timestamp and duration, got %+v", event)
+	}
+
+	return event
+}
+
+func ignoreTiming() cmp.Option {
+	return cmpopts.IgnoreFields(audit.Event{}, "Timestamp", "DurationSeconds")
+}
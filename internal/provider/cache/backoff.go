@@ -0,0 +1,106 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// ErrorClassUnknown is reported for errors which do not report their class.
+	ErrorClassUnknown = "unknown"
+	// ErrorClassTimeout is reported for errors caused by exceeded deadline.
+	ErrorClassTimeout = "timeout"
+)
+
+// classifiedError is implemented by errors which report their class, so failures can be accounted separately.
+type classifiedError interface {
+	ErrorClass() string
+}
+
+// errorClass returns class of the given error.
+func errorClass(err error) string {
+	var classified classifiedError
+	if errors.As(err, &classified) {
+		return classified.ErrorClass()
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+
+	return ErrorClassUnknown
+}
+
+// backingOff returns the failure cached in the given entry of the given metric, if the entry is still backing off.
+func (p *cacheProvider) backingOff(entry *Entry, metric string) *Failure {
+	if p.errorBackoff <= 0 || entry == nil || entry.Failure == nil {
+		return nil
+	}
+
+	failure := entry.Failure
+	if !p.clock.Now().Before(failure.Until) {
+		return nil
+	}
+
+	p.cacheMetrics.requestTotal.WithLabelValues(p.metricLabel(metric), cacheResultNegativeHit).Inc()
+	p.cacheMetrics.backendCallsAvoided.WithLabelValues(failure.Class).Inc()
+
+	return failure
+}
+
+// recordFailure caches the error in the entry of the given key, so all replicas sharing the storage back off.
+// The backoff period doubles with each consecutive failure. Failures are no longer consecutive once the maximum
+// backoff period passes after the previous backoff ended.
+func (p *cacheProvider) recordFailure(
+	ctx context.Context, id string, previous *Entry, match labels.Selector, metric string, err error,
+) {
+	// Canceled requests say nothing about the health of the external provider.
+	if p.errorBackoff <= 0 || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	now := p.clock.Now()
+	failures := 1
+
+	if previous != nil && previous.Failure != nil && now.Before(previous.Failure.Until.Add(p.maxErrorBackoff)) {
+		failures += previous.Failure.Failures
+	}
+
+	backoff := p.errorBackoff
+	for i := 1; i < failures && backoff < p.maxErrorBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > p.maxErrorBackoff {
+		backoff = p.maxErrorBackoff
+	}
+
+	// The previously fetched value is kept, so it can still be inspected.
+	entry := &Entry{Metric: metric, Selector: selectorString(match)}
+	if previous != nil {
+		copied := *previous
+		entry = &copied
+	}
+
+	entry.Failure = &Failure{
+		Status:   *responsewriters.ErrorToAPIStatus(err),
+		Class:    errorClass(err),
+		Failures: failures,
+		FailedAt: now,
+		Until:    now.Add(backoff),
+		err:      err,
+	}
+
+	if err := p.storage.Store(ctx, id, entry); err != nil {
+		klog.ErrorS(err, "Storing cache entry failure", logkeys.Key, id)
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap used by the shared storage when none is configured.
+	DefaultConfigMapName = "newrelic-k8s-metrics-adapter-cache"
+
+	// DefaultLockTTL is the time after which a lock held by a replica which did not release it is considered stale.
+	DefaultLockTTL = 30 * time.Second
+
+	// DefaultMaxEntryAge is the time after which entries are removed from the ConfigMap.
+	DefaultMaxEntryAge = time.Hour
+
+	// maxDataSize is the size of ConfigMap data above which the oldest entries are removed, leaving headroom
+	// below the 1 MiB limit of Kubernetes objects for metadata and locks.
+	maxDataSize = 900 * 1024
+
+	entryKeyPrefix = "entry."
+	lockKeyPrefix  = "lock."
+)
+
+// ConfigMapStorageOptions holds the configuration of the ConfigMap backed storage.
+type ConfigMapStorageOptions struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	// Holder identifies the replica holding the refresh locks, usually the Pod name.
+	Holder  string
+	LockTTL time.Duration
+	// MaxEntryAge is the time since the last update after which entries are removed. Defaults to
+	// DefaultMaxEntryAge.
+	MaxEntryAge time.Duration
+}
+
+// configMapStorage keeps entries in a single ConfigMap, so they can be shared between adapter replicas.
+// All writes use optimistic concurrency, so concurrent replicas never overwrite each other's changes.
+// Reads are served from an informer cache, so waiting for entries refreshed by other replicas does not
+// load the API server.
+type configMapStorage struct {
+	client      corev1client.ConfigMapInterface
+	lister      corev1listers.ConfigMapNamespaceLister
+	synced      func() bool
+	namespace   string
+	name        string
+	holder      string
+	lockTTL     time.Duration
+	maxEntryAge time.Duration
+	// localLocks holds keys locked by this replica, as the holder of the ConfigMap lock is shared by all
+	// goroutines of the replica.
+	localLocks *sync.Map
+}
+
+// storedEntry is the entry kept in the ConfigMap together with its key, as data keys only hold a hash of it.
+type storedEntry struct {
+	Key string `json:"key"`
+	*Entry
+}
+
+type lockRecord struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// NewConfigMapStorage returns storage keeping entries in a ConfigMap shared by all adapter replicas. The
+// ConfigMap is watched until the given context is done.
+func NewConfigMapStorage(ctx context.Context, options ConfigMapStorageOptions) (Storage, error) {
+	if options.Client == nil {
+		return nil, fmt.Errorf("a ConfigMap client cannot be nil")
+	}
+
+	if options.Namespace == "" {
+		return nil, fmt.Errorf("a ConfigMap namespace cannot be empty")
+	}
+
+	if options.Holder == "" {
+		return nil, fmt.Errorf("a lock holder identity cannot be empty")
+	}
+
+	name := options.Name
+	if name == "" {
+		name = DefaultConfigMapName
+	}
+
+	lockTTL := options.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = DefaultLockTTL
+	}
+
+	maxEntryAge := options.MaxEntryAge
+	if maxEntryAge <= 0 {
+		maxEntryAge = DefaultMaxEntryAge
+	}
+
+	klog.InfoS("Sharing cache using ConfigMap", logkeys.ConfigMap, klog.KRef(options.Namespace, name),
+		logkeys.Holder, options.Holder)
+
+	// Only the ConfigMap holding the cache is watched.
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(options.Client, 0,
+		informers.WithNamespace(options.Namespace),
+		informers.WithTweakListOptions(func(listOptions *metav1.ListOptions) {
+			listOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+	// Informer must be requested before the factory is started, so it is started as well.
+	informer := informerFactory.Core().V1().ConfigMaps()
+	synced := informer.Informer().HasSynced
+
+	informerFactory.Start(ctx.Done())
+
+	return &configMapStorage{
+		client:      options.Client.CoreV1().ConfigMaps(options.Namespace),
+		lister:      informer.Lister().ConfigMaps(options.Namespace),
+		synced:      synced,
+		namespace:   options.Namespace,
+		name:        name,
+		holder:      options.Holder,
+		lockTTL:     lockTTL,
+		maxEntryAge: maxEntryAge,
+		localLocks:  &sync.Map{},
+	}, nil
+}
+
+// get returns the ConfigMap from the informer cache. Until the cache is synced, it is read from the API server.
+// Returned ConfigMap must not be modified.
+func (s *configMapStorage) get(ctx context.Context) (*corev1.ConfigMap, error) {
+	if s.synced() {
+		return s.lister.Get(s.name) //nolint:wrapcheck // Errors are wrapped by the callers.
+	}
+
+	return s.client.Get(ctx, s.name, metav1.GetOptions{}) //nolint:wrapcheck // Errors are wrapped by the callers.
+}
+
+func (s *configMapStorage) Load(ctx context.Context, key string) (*Entry, bool, error) {
+	cm, err := s.get(ctx)
+	if apierrors.IsNotFound(err) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("getting ConfigMap: %w", err)
+	}
+
+	raw, ok := cm.Data[entryKeyPrefix+hashKey(key)]
+	if !ok {
+		return nil, false, nil
+	}
+
+	stored := &storedEntry{Entry: &Entry{}}
+	if err := json.Unmarshal([]byte(raw), stored); err != nil {
+		return nil, false, fmt.Errorf("unmarshalling entry %q: %w", key, err)
+	}
+
+	// Entry of a colliding key is never returned.
+	if stored.Key != key {
+		return nil, false, nil
+	}
+
+	return stored.Entry, true, nil
+}
+
+func (s *configMapStorage) Store(ctx context.Context, key string, entry *Entry) error {
+	raw, err := json.Marshal(&storedEntry{Key: key, Entry: entry})
+	if err != nil {
+		return fmt.Errorf("marshalling entry %q: %w", key, err)
+	}
+
+	return s.update(ctx, func(data map[string]string) bool {
+		data[entryKeyPrefix+hashKey(key)] = string(raw)
+
+		s.evict(data, time.Now())
+
+		return true
+	})
+}
+
+// evict removes entries older than the maximum entry age and expired locks from the given data. If the data
+// still exceeds the size limit, the oldest entries are removed until it fits, so writes do not start failing.
+func (s *configMapStorage) evict(data map[string]string, now time.Time) {
+	type dataEntry struct {
+		dataKey   string
+		key       string
+		updatedAt time.Time
+	}
+
+	entries := []dataEntry{}
+	size := 0
+
+	for dataKey, raw := range data {
+		switch {
+		case strings.HasPrefix(dataKey, entryKeyPrefix):
+			stored := &storedEntry{Entry: &Entry{}}
+			if err := json.Unmarshal([]byte(raw), stored); err != nil || now.Sub(stored.updatedAt()) > s.maxEntryAge {
+				delete(data, dataKey)
+
+				continue
+			}
+
+			entries = append(entries, dataEntry{dataKey: dataKey, key: stored.Key, updatedAt: stored.updatedAt()})
+		case strings.HasPrefix(dataKey, lockKeyPrefix):
+			lock := &lockRecord{}
+			if err := json.Unmarshal([]byte(raw), lock); err != nil || now.After(lock.ExpiresAt) {
+				delete(data, dataKey)
+
+				continue
+			}
+		}
+
+		size += len(dataKey) + len(raw)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].updatedAt.Before(entries[j].updatedAt) })
+
+	for _, entry := range entries {
+		if size <= maxDataSize {
+			return
+		}
+
+		size -= len(entry.dataKey) + len(data[entry.dataKey])
+		delete(data, entry.dataKey)
+
+		klog.V(debug).InfoS("Evicted oldest cache entry as ConfigMap reached size limit", logkeys.Key, entry.key)
+	}
+}
+
+func (s *configMapStorage) Delete(ctx context.Context, key string) error {
+	return s.update(ctx, func(data map[string]string) bool {
+		entryKey := entryKeyPrefix + hashKey(key)
+
+		if _, ok := data[entryKey]; !ok {
+			return false
+		}
+
+		delete(data, entryKey)
+
+		return true
+	})
+}
+
+func (s *configMapStorage) List(ctx context.Context) (map[string]*Entry, error) {
+	entries := map[string]*Entry{}
+
+	cm, err := s.get(ctx)
+	if apierrors.IsNotFound(err) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting ConfigMap: %w", err)
+	}
+
+	for dataKey, raw := range cm.Data {
+		if !strings.HasPrefix(dataKey, entryKeyPrefix) {
+			continue
+		}
+
+		stored := &storedEntry{Entry: &Entry{}}
+		if err := json.Unmarshal([]byte(raw), stored); err != nil {
+			return nil, fmt.Errorf("unmarshalling entry %q: %w", dataKey, err)
+		}
+
+		entries[stored.Key] = stored.Entry
+	}
+
+	return entries, nil
+}
+
+func (s *configMapStorage) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	// The ConfigMap lock cannot tell goroutines of this replica apart, as they share the holder.
+	if _, locked := s.localLocks.LoadOrStore(key, struct{}{}); locked {
+		return nil, false, nil
+	}
+
+	unlock, acquired, err := s.tryLockConfigMap(ctx, key)
+	if err != nil || !acquired {
+		s.localLocks.Delete(key)
+
+		return nil, false, err
+	}
+
+	return func() {
+		unlock()
+		s.localLocks.Delete(key)
+	}, true, nil
+}
+
+// tryLockConfigMap attempts to acquire the lock of the given key held in the ConfigMap. The lock of this
+// holder is re-acquired, so a lock left behind by a previous instance of the replica does not need to expire.
+func (s *configMapStorage) tryLockConfigMap(ctx context.Context, key string) (func(), bool, error) {
+	lockKey := lockKeyPrefix + hashKey(key)
+	acquired := false
+
+	err := s.update(ctx, func(data map[string]string) bool {
+		acquired = false
+
+		if raw, ok := data[lockKey]; ok {
+			current := &lockRecord{}
+			if err := json.Unmarshal([]byte(raw), current); err == nil &&
+				current.Holder != s.holder && time.Now().Before(current.ExpiresAt) {
+				return false
+			}
+		}
+
+		raw, err := json.Marshal(&lockRecord{Holder: s.holder, ExpiresAt: time.Now().Add(s.lockTTL)})
+		if err != nil {
+			return false
+		}
+
+		data[lockKey] = string(raw)
+		acquired = true
+
+		return true
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("acquiring lock for %q: %w", key, err)
+	}
+
+	if !acquired {
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// Lock must be released even if the request context has been canceled in the meantime.
+		err := s.update(context.Background(), func(data map[string]string) bool {
+			current := &lockRecord{}
+			if err := json.Unmarshal([]byte(data[lockKey]), current); err != nil || current.Holder != s.holder {
+				return false
+			}
+
+			delete(data, lockKey)
+
+			return true
+		})
+		if err != nil {
+			klog.ErrorS(err, "Releasing lock", logkeys.Key, key)
+		}
+	}
+
+	return unlock, true, nil
+}
+
+// update applies given mutation to the ConfigMap data, creating the ConfigMap if it does not exist yet.
+// Mutation function returns false when no update should be performed.
+func (s *configMapStorage) update(ctx context.Context, mutate func(data map[string]string) bool) error {
+	//nolint:wrapcheck // Errors are wrapped by the callers.
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+				},
+				Data: map[string]string{},
+			}
+
+			if !mutate(cm.Data) {
+				return nil
+			}
+
+			_, err = s.client.Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// Retry as an update as someone else created the ConfigMap in the meantime.
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+
+		if !mutate(cm.Data) {
+			return nil
+		}
+
+		_, err = s.client.Update(ctx, cm, metav1.UpdateOptions{})
+
+		return err
+	})
+}
+
+// hashKey converts cache key into a valid ConfigMap data key. Selectors may contain characters which are not
+// allowed there and may exceed the length limit of data keys, so a fixed-length hash of the key is used.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,420 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const testNamespace = "test-namespace"
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_ConfigMap_storage(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("returns_stored_entry_to_other_replicas", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		first := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first"})
+		second := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "second"})
+
+		key := "metric/key in (a,b)"
+		entry := &cache.Entry{
+			Value: &external_metrics.ExternalMetricValueList{
+				Items: []external_metrics.ExternalMetricValue{{MetricName: "metric", Value: resource.MustParse("5")}},
+			},
+			Timestamp: metav1.Now(),
+			FetchedAt: time.Now(),
+		}
+
+		if err := first.Store(ctx, key, entry); err != nil {
+			t.Fatalf("Unexpected error storing entry: %v", err)
+		}
+
+		stored := waitForEntry(ctx, t, second, key)
+
+		if v := stored.Value.Items[0].Value.String(); v != "5" {
+			t.Fatalf("Expected value %q, got %q", "5", v)
+		}
+	})
+
+	t.Run("returns_no_entry_when_key_was_never_stored", func(t *testing.T) {
+		t.Parallel()
+
+		s := testConfigMapStorage(t, fake.NewClientset(), cache.ConfigMapStorageOptions{Holder: "first"})
+
+		if _, ok, err := s.Load(ctx, "foo"); err != nil || ok {
+			t.Fatalf("Expected no entry and no error, got %v and %v", ok, err)
+		}
+	})
+
+	t.Run("allows_only_one_replica_to_lock_key", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		first := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first"})
+		second := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "second"})
+
+		unlock, ok, err := first.TryLock(ctx, "foo")
+		if err != nil || !ok {
+			t.Fatalf("Expected first replica to acquire lock, got %v and %v", ok, err)
+		}
+
+		if _, ok, err := second.TryLock(ctx, "foo"); err != nil || ok {
+			t.Fatalf("Expected second replica to not acquire lock, got %v and %v", ok, err)
+		}
+
+		if _, ok, err := second.TryLock(ctx, "bar"); err != nil || !ok {
+			t.Fatalf("Expected second replica to acquire lock for other key, got %v and %v", ok, err)
+		}
+
+		unlock()
+
+		if _, ok, err := second.TryLock(ctx, "foo"); err != nil || !ok {
+			t.Fatalf("Expected second replica to acquire released lock, got %v and %v", ok, err)
+		}
+	})
+
+	t.Run("allows_other_replica_to_lock_key_when_lock_expires", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		first := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first", LockTTL: time.Millisecond})
+		second := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "second", LockTTL: time.Millisecond})
+
+		if _, ok, err := first.TryLock(ctx, "foo"); err != nil || !ok {
+			t.Fatalf("Expected first replica to acquire lock, got %v and %v", ok, err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		if _, ok, err := second.TryLock(ctx, "foo"); err != nil || !ok {
+			t.Fatalf("Expected second replica to acquire expired lock, got %v and %v", ok, err)
+		}
+	})
+
+	t.Run("allows_only_one_goroutine_of_replica_to_lock_key", func(t *testing.T) {
+		t.Parallel()
+
+		s := testConfigMapStorage(t, fake.NewClientset(), cache.ConfigMapStorageOptions{Holder: "first"})
+
+		unlock, ok, err := s.TryLock(ctx, "foo")
+		if err != nil || !ok {
+			t.Fatalf("Expected lock to be acquired, got %v and %v", ok, err)
+		}
+
+		if _, ok, err := s.TryLock(ctx, "foo"); err != nil || ok {
+			t.Fatalf("Expected locked key to not be locked again, got %v and %v", ok, err)
+		}
+
+		unlock()
+
+		if _, ok, err := s.TryLock(ctx, "foo"); err != nil || !ok {
+			t.Fatalf("Expected released lock to be acquired, got %v and %v", ok, err)
+		}
+	})
+
+	t.Run("serves_loads_from_informer_cache", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		s := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first"})
+
+		if err := s.Store(ctx, "foo", testEntry(time.Now())); err != nil {
+			t.Fatalf("Unexpected error storing entry: %v", err)
+		}
+
+		// Until the informer cache is synced, entries are loaded from the API server.
+		for !loadsFromCache(ctx, t, client, s) {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("Entries still loaded from API server at test deadline")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+
+		for i := 0; i < 10; i++ {
+			if !loadsFromCache(ctx, t, client, s) {
+				t.Fatalf("Expected no requests to API server, got %v", client.Actions())
+			}
+		}
+	})
+
+	t.Run("evicts_entries_older_than_max_entry_age", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		s := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first", MaxEntryAge: time.Minute})
+
+		if err := s.Store(ctx, "old", testEntry(time.Now().Add(-2*time.Minute))); err != nil {
+			t.Fatalf("Unexpected error storing entry: %v", err)
+		}
+
+		if err := s.Store(ctx, "new", testEntry(time.Now())); err != nil {
+			t.Fatalf("Unexpected error storing entry: %v", err)
+		}
+
+		if entries := storedEntries(ctx, t, client); entries != 1 {
+			t.Fatalf("Expected only fresh entry to be stored, got %d entries", entries)
+		}
+	})
+
+	t.Run("evicts_oldest_entries_when_ConfigMap_reaches_size_limit", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		s := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first"})
+
+		start := time.Now().Add(-time.Minute)
+
+		// Each entry takes over 100 KiB, so all of them do not fit within the size limit of ConfigMaps.
+		for i := 0; i < 10; i++ {
+			entry := testEntry(start.Add(time.Duration(i) * time.Second))
+			entry.Selector = strings.Repeat("a", 100*1024)
+
+			if err := s.Store(ctx, fmt.Sprintf("key-%d", i), entry); err != nil {
+				t.Fatalf("Unexpected error storing entry %d: %v", i, err)
+			}
+		}
+
+		if entries := storedEntries(ctx, t, client); entries >= 10 || entries == 0 {
+			t.Fatalf("Expected some entries to be evicted, got %d entries", entries)
+		}
+
+		if entry := waitForEntry(ctx, t, s, "key-9"); entry == nil {
+			t.Fatalf("Expected newest entry to be kept")
+		}
+
+		if _, ok, err := s.Load(ctx, "key-0"); err != nil || ok {
+			t.Fatalf("Expected oldest entry to be evicted, got %v and %v", ok, err)
+		}
+	})
+
+	t.Run("stores_entries_of_keys_exceeding_length_limit_of_data_keys", func(t *testing.T) {
+		t.Parallel()
+
+		client := fake.NewClientset()
+		s := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: "first"})
+
+		key := "metric/key in (" + strings.Repeat("a", 300) + ")"
+
+		if err := s.Store(ctx, key, testEntry(time.Now())); err != nil {
+			t.Fatalf("Unexpected error storing entry: %v", err)
+		}
+
+		cm, err := client.CoreV1().ConfigMaps(testNamespace).Get(ctx, cache.DefaultConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Getting ConfigMap: %v", err)
+		}
+
+		for dataKey := range cm.Data {
+			if errs := validation.IsConfigMapKey(dataKey); len(errs) > 0 {
+				t.Fatalf("Expected valid data key, got %q: %v", dataKey, errs)
+			}
+		}
+
+		waitForEntry(ctx, t, s, key)
+
+		entries, err := s.List(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error listing entries: %v", err)
+		}
+
+		if _, ok := entries[key]; !ok || len(entries) != 1 {
+			t.Fatalf("Expected entry to be listed under original key, got %v", entries)
+		}
+	})
+}
+
+func Test_Cache_providers_sharing_ConfigMap_storage_query_external_provider_once(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	var numCalls int32
+
+	mockProvider := &mock.Provider{
+		GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+			atomic.AddInt32(&numCalls, 1)
+
+			// Simulate slow backend, so replicas request the value at the same time.
+			time.Sleep(200 * time.Millisecond)
+
+			return &external_metrics.ExternalMetricValueList{
+				Items: []external_metrics.ExternalMetricValue{
+					{Timestamp: metav1.Now(), Value: resource.MustParse("1")},
+				},
+			}, nil
+		},
+	}
+
+	client := fake.NewClientset()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 3; i++ {
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{
+			ExternalProvider: mockProvider,
+			CacheTTLSeconds:  30,
+			Storage:          testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: fmt.Sprintf("replica-%d", i)}),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne}); err != nil {
+				t.Errorf("Unexpected error while getting external metric: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&numCalls); n != 1 {
+		t.Fatalf("Expected exactly 1 call to backend, got %d", n)
+	}
+}
+
+func Test_Creating_ConfigMap_storage_returns_error_when(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]func(*cache.ConfigMapStorageOptions){
+		"client_is_not_set":    func(o *cache.ConfigMapStorageOptions) { o.Client = nil },
+		"namespace_is_not_set": func(o *cache.ConfigMapStorageOptions) { o.Namespace = "" },
+		"holder_is_not_set":    func(o *cache.ConfigMapStorageOptions) { o.Holder = "" },
+	}
+
+	for testCaseName, mutateF := range cases {
+		mutateF := mutateF
+
+		t.Run(testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			options := cache.ConfigMapStorageOptions{
+				Client:    fake.NewClientset(),
+				Namespace: testNamespace,
+				Holder:    "foo",
+			}
+
+			mutateF(&options)
+
+			if _, err := cache.NewConfigMapStorage(testutil.ContextWithDeadline(t), options); err == nil {
+				t.Fatalf("Expected error creating storage")
+			}
+		})
+	}
+}
+
+// testConfigMapStorage returns storage of the ConfigMap in the test namespace with the given options.
+func testConfigMapStorage(t *testing.T, client *fake.Clientset, options cache.ConfigMapStorageOptions) cache.Storage {
+	t.Helper()
+
+	options.Client = client
+	options.Namespace = testNamespace
+
+	s, err := cache.NewConfigMapStorage(testutil.ContextWithDeadline(t), options)
+	if err != nil {
+		t.Fatalf("Unexpected error creating storage: %v", err)
+	}
+
+	return s
+}
+
+// waitForEntry waits until the entry stored under the given key is visible in the given storage, as
+// ConfigMap changes are observed asynchronously.
+func waitForEntry(ctx context.Context, t *testing.T, s cache.Storage, key string) *cache.Entry {
+	t.Helper()
+
+	for {
+		entry, ok, err := s.Load(ctx, key)
+		if err != nil {
+			t.Fatalf("Unexpected error loading entry: %v", err)
+		}
+
+		if ok {
+			return entry
+		}
+
+		select {
+		case <-ctx.Done():
+			t.Fatalf("Entry %q not found before test deadline", key)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// loadsFromCache reports whether the stored entry was loaded without requests to the API server.
+func loadsFromCache(ctx context.Context, t *testing.T, client *fake.Clientset, s cache.Storage) bool {
+	t.Helper()
+
+	client.ClearActions()
+
+	if _, ok, err := s.Load(ctx, "foo"); err != nil || !ok {
+		t.Fatalf("Expected entry to be loaded, got %v and %v", ok, err)
+	}
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "get" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// storedEntries returns the number of entries held by the ConfigMap in the API server.
+func storedEntries(ctx context.Context, t *testing.T, client *fake.Clientset) int {
+	t.Helper()
+
+	cm, err := client.CoreV1().ConfigMaps(testNamespace).Get(ctx, cache.DefaultConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Getting ConfigMap: %v", err)
+	}
+
+	entries := 0
+
+	for key := range cm.Data {
+		if strings.HasPrefix(key, "entry.") {
+			entries++
+		}
+	}
+
+	return entries
+}
+
+func testEntry(fetchedAt time.Time) *cache.Entry {
+	return &cache.Entry{
+		Value: &external_metrics.ExternalMetricValueList{
+			Items: []external_metrics.ExternalMetricValue{{MetricName: "metric", Value: resource.MustParse("5")}},
+		},
+		Timestamp: metav1.NewTime(fetchedAt),
+		FetchedAt: fetchedAt,
+	}
+}
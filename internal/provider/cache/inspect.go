@@ -0,0 +1,227 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+// InspectionPath is the path under which the cache inspection handler should be served.
+//
+// GET request lists all cache entries. DELETE request with "key" query parameter purges a single entry and
+// with "metric" query parameter purges all entries of a given metric.
+const InspectionPath = "/debug/cache"
+
+type inspectedEntry struct {
+	Key          string     `json:"key"`
+	Metric       string     `json:"metric,omitempty"`
+	Selector     string     `json:"selector,omitempty"`
+	Value        string     `json:"value,omitempty"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	AgeSeconds   float64    `json:"ageSeconds,omitempty"`
+	FetchedAt    *time.Time `json:"fetchedAt,omitempty"`
+	Hits         int64      `json:"hits"`
+	Error        string     `json:"error,omitempty"`
+	BackoffUntil *time.Time `json:"backoffUntil,omitempty"`
+}
+
+type purgeResult struct {
+	Purged int `json:"purged"`
+}
+
+// InspectionHandler returns HTTP handler for inspecting and purging entries of the given provider.
+// If the given provider is not a cache provider, e.g. because caching is disabled, nil is returned.
+func InspectionHandler(p provider.ExternalMetricsProvider) http.Handler {
+	cp, ok := p.(*cacheProvider)
+	if !ok {
+		return nil
+	}
+
+	return http.HandlerFunc(cp.serveInspection)
+}
+
+// MetricStats holds statistics of cache entries of a single metric.
+type MetricStats struct {
+	// Entries is the number of cached values, one per selector.
+	Entries int `json:"entries"`
+	// Hits is the number of requests served from the cache by this replica.
+	Hits int64 `json:"hits"`
+	// BackingOff is the number of selectors which failures are cached.
+	BackingOff int `json:"backingOff"`
+}
+
+// Stats returns statistics of cache entries of each metric of the given provider. If the given provider is not
+// a cache provider, e.g. because caching is disabled, nil is returned.
+func Stats(ctx context.Context, p provider.ExternalMetricsProvider) (map[string]MetricStats, error) {
+	cp, ok := p.(*cacheProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	entries, err := cp.inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]MetricStats{}
+
+	for _, e := range entries {
+		// Metric names cannot contain slashes, so the metric is the part of the key before the selector.
+		metric, _, _ := strings.Cut(e.Key, "/")
+
+		metricStats := stats[metric]
+		metricStats.Hits += e.Hits
+
+		if e.FetchedAt != nil {
+			metricStats.Entries++
+		}
+
+		if e.BackoffUntil != nil && cp.clock.Now().Before(*e.BackoffUntil) {
+			metricStats.BackingOff++
+		}
+
+		stats[metric] = metricStats
+	}
+
+	return stats, nil
+}
+
+func (p *cacheProvider) serveInspection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		entries, err := p.inspect(r.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("listing cache entries: %v", err), http.StatusInternalServerError)
+
+			return
+		}
+
+		writeJSON(w, entries)
+	case http.MethodDelete:
+		key := r.URL.Query().Get("key")
+		metric := r.URL.Query().Get("metric")
+
+		if (key == "") == (metric == "") {
+			http.Error(w, "exactly one of 'key' or 'metric' query parameters must be specified", http.StatusBadRequest)
+
+			return
+		}
+
+		purged, err := p.purge(r.Context(), func(id string) bool {
+			return id == key || (metric != "" && (id == metric || strings.HasPrefix(id, metric+"/")))
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("purging cache entries: %v", err), http.StatusInternalServerError)
+
+			return
+		}
+
+		klog.InfoS("Purged cache entries", logkeys.Count, purged, logkeys.Key, key, logkeys.Metric, metric)
+
+		writeJSON(w, purgeResult{Purged: purged})
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodDelete}, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *cacheProvider) inspect(ctx context.Context) ([]inspectedEntry, error) {
+	stored, err := p.storage.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing storage: %w", err)
+	}
+
+	entries := make([]inspectedEntry, 0, len(stored))
+
+	for id, entry := range stored {
+		e := inspectedEntry{
+			Key:      id,
+			Metric:   entry.Metric,
+			Selector: entry.Selector,
+			Hits:     p.hitCount(id),
+		}
+
+		if entry.Value != nil {
+			timestamp := entry.Timestamp.Time
+			fetchedAt := entry.FetchedAt
+
+			e.Timestamp = &timestamp
+			e.AgeSeconds = p.clock.Since(timestamp).Seconds()
+			e.FetchedAt = &fetchedAt
+
+			if len(entry.Value.Items) > 0 {
+				e.Value = entry.Value.Items[0].Value.String()
+			}
+		}
+
+		if entry.Failure != nil {
+			until := entry.Failure.Until
+
+			e.Error = entry.Failure.Err().Error()
+			e.BackoffUntil = &until
+		}
+
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	return entries, nil
+}
+
+func (p *cacheProvider) purge(ctx context.Context, matches func(id string) bool) (int, error) {
+	stored, err := p.storage.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing storage: %w", err)
+	}
+
+	purged := 0
+
+	for id := range stored {
+		if !matches(id) {
+			continue
+		}
+
+		if err := p.storage.Delete(ctx, id); err != nil {
+			return purged, fmt.Errorf("deleting entry %q: %w", id, err)
+		}
+
+		p.hits.Delete(id)
+
+		purged++
+	}
+
+	p.updateSize(ctx)
+
+	return purged, nil
+}
+
+func (p *cacheProvider) hitCount(id string) int64 {
+	hits, ok := p.hits.Load(id)
+	if !ok {
+		return 0
+	}
+
+	return atomic.LoadInt64(hits.(*int64)) //nolint:forcetypeassert // Hits should always be of this type.
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.ErrorS(err, "Writing response")
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+type testInspectedEntry struct {
+	Key      string `json:"key"`
+	Metric   string `json:"metric"`
+	Selector string `json:"selector"`
+	Value    string `json:"value"`
+	Hits     int64  `json:"hits"`
+}
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Cache_inspection_handler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists_cached_entries_with_hit_counts", func(t *testing.T) {
+		t.Parallel()
+
+		handler := populatedInspectionHandler(t)
+
+		entries := listInspectedEntries(t, handler)
+
+		if len(entries) != 3 {
+			t.Fatalf("Expected 3 entries, got %d: %v", len(entries), entries)
+		}
+
+		expected := testInspectedEntry{
+			Key:      testMetricNameOne + "/foo=bar",
+			Metric:   testMetricNameOne,
+			Selector: "foo=bar",
+			Value:    "1",
+			Hits:     1,
+		}
+
+		if entries[0] != expected {
+			t.Fatalf("Expected entry %+v, got %+v", expected, entries[0])
+		}
+	})
+
+	t.Run("purges_single_key", func(t *testing.T) {
+		t.Parallel()
+
+		handler := populatedInspectionHandler(t)
+
+		if code := purge(t, handler, url.Values{"key": []string{testMetricNameOne + "/foo=bar"}}); code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, code)
+		}
+
+		if entries := listInspectedEntries(t, handler); len(entries) != 2 {
+			t.Fatalf("Expected 2 entries left, got %d: %v", len(entries), entries)
+		}
+	})
+
+	t.Run("purges_all_keys_of_metric", func(t *testing.T) {
+		t.Parallel()
+
+		handler := populatedInspectionHandler(t)
+
+		if code := purge(t, handler, url.Values{"metric": []string{testMetricNameOne}}); code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, code)
+		}
+
+		entries := listInspectedEntries(t, handler)
+		if len(entries) != 1 || entries[0].Metric != testMetricNameTwo {
+			t.Fatalf("Expected only entry of metric %q to be left, got %v", testMetricNameTwo, entries)
+		}
+	})
+
+	t.Run("rejects_purge_without_key_or_metric", func(t *testing.T) {
+		t.Parallel()
+
+		if code := purge(t, populatedInspectionHandler(t), url.Values{}); code != http.StatusBadRequest {
+			t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("rejects_unsupported_method", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		populatedInspectionHandler(t).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, cache.InspectionPath, nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("Expected status code %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+		}
+	})
+}
+
+func Test_Purging_entries_added_by_other_replica_sets_cache_size_from_shared_storage(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+	storage := cache.NewMemoryStorage()
+
+	mockProvider := &mock.Provider{
+		GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+			return &external_metrics.ExternalMetricValueList{
+				Items: []external_metrics.ExternalMetricValue{{Timestamp: metav1.Now(), Value: resource.MustParse("1")}},
+			}, nil
+		},
+	}
+
+	registries := []metrics.KubeRegistry{}
+	providers := []provider.ExternalMetricsProvider{}
+
+	for i := 0; i < 2; i++ {
+		registry := metrics.NewKubeRegistry()
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{
+			ExternalProvider: mockProvider,
+			CacheTTLSeconds:  30,
+			Storage:          storage,
+			RegisterFunc:     registry.Register,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		registries = append(registries, registry)
+		providers = append(providers, p)
+	}
+
+	info := provider.ExternalMetricInfo{Metric: testMetricNameOne}
+
+	for _, selector := range []string{"foo=bar", "foo=baz"} {
+		sl, err := labels.Parse(selector)
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		if _, err := providers[0].GetExternalMetric(ctx, "", sl, info); err != nil {
+			t.Fatalf("Unexpected error while getting external metric: %v", err)
+		}
+	}
+
+	handler := cache.InspectionHandler(providers[1])
+
+	if code := purge(t, handler, url.Values{"key": []string{testMetricNameOne + "/foo=bar"}}); code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, code)
+	}
+
+	expected := `
+# HELP newrelic_adapter_external_provider_cache_size [ALPHA] Number of external metrics entries stored in the cache.
+# TYPE newrelic_adapter_external_provider_cache_size gauge
+newrelic_adapter_external_provider_cache_size 1
+`
+
+	if err := metricsTestutil.GatherAndCompare(registries[1], bytes.NewBufferString(expected),
+		"newrelic_adapter_external_provider_cache_size",
+	); err != nil {
+		t.Fatalf("Unexpected cache size: %v", err)
+	}
+}
+
+func Test_Hit_counts_of_entries_removed_from_shared_storage_are_dropped(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+	storage := cache.NewMemoryStorage()
+
+	mockProvider := &mock.Provider{
+		GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+			return &external_metrics.ExternalMetricValueList{
+				Items: []external_metrics.ExternalMetricValue{{Timestamp: metav1.Now(), Value: resource.MustParse("1")}},
+			}, nil
+		},
+	}
+
+	providers := []provider.ExternalMetricsProvider{}
+
+	for i := 0; i < 2; i++ {
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{
+			ExternalProvider: mockProvider,
+			CacheTTLSeconds:  30,
+			Storage:          storage,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		providers = append(providers, p)
+	}
+
+	info := provider.ExternalMetricInfo{Metric: testMetricNameOne}
+
+	get := func(selector string) {
+		t.Helper()
+
+		sl, err := labels.Parse(selector)
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		if _, err := providers[0].GetExternalMetric(ctx, "", sl, info); err != nil {
+			t.Fatalf("Unexpected error while getting external metric: %v", err)
+		}
+	}
+
+	// Miss followed by a hit.
+	get("foo=bar")
+	get("foo=bar")
+
+	// Entry removed by other replica is not known to this replica until it stores an entry.
+	if code := purge(t, cache.InspectionHandler(providers[1]), url.Values{"key": []string{testMetricNameOne + "/foo=bar"}}); code != http.StatusOK { //nolint:lll // Just a long call.
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, code)
+	}
+
+	get("foo=baz")
+	get("foo=bar")
+
+	for _, entry := range listInspectedEntries(t, cache.InspectionHandler(providers[0])) {
+		if entry.Hits != 0 {
+			t.Errorf("Expected no hits of entry %q, got %d", entry.Key, entry.Hits)
+		}
+	}
+}
+
+func Test_Cache_inspection_handler_is_not_available_when_provider_is_not_cache_provider(t *testing.T) {
+	t.Parallel()
+
+	if handler := cache.InspectionHandler(&mock.Provider{}); handler != nil {
+		t.Fatalf("Expected no handler, got %v", handler)
+	}
+}
+
+func populatedInspectionHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	p, _, _ := getTestCacheProvider(t, 30)
+
+	requests := []struct {
+		metric   string
+		selector labels.Selector
+	}{
+		{testMetricNameOne, labels.SelectorFromSet(labels.Set{"foo": "bar"})},
+		{testMetricNameOne, labels.SelectorFromSet(labels.Set{"foo": "baz"})},
+		{testMetricNameTwo, labels.SelectorFromSet(labels.Set{"foo": "bar"})},
+		// Served from cache, so first entry gets a hit.
+		{testMetricNameOne, labels.SelectorFromSet(labels.Set{"foo": "bar"})},
+	}
+
+	for _, r := range requests {
+		if _, err := p.GetExternalMetric(ctx, "", r.selector, provider.ExternalMetricInfo{Metric: r.metric}); err != nil {
+			t.Fatalf("Unexpected error while getting external metric: %v", err)
+		}
+	}
+
+	handler := cache.InspectionHandler(p)
+	if handler == nil {
+		t.Fatalf("Expected inspection handler for cache provider")
+	}
+
+	return handler
+}
+
+func listInspectedEntries(t *testing.T, handler http.Handler) []testInspectedEntry {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, cache.InspectionPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	entries := []testInspectedEntry{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Decoding response: %v", err)
+	}
+
+	return entries
+}
+
+func purge(t *testing.T, handler http.Handler, query url.Values) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, cache.InspectionPath+"?"+query.Encode(), nil))
+
+	return rec.Code
+}
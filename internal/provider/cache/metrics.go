@@ -13,11 +13,27 @@ const (
 	// MetricsSubsystem groups metrics coming from cache provider.
 	MetricsSubsystem = "external_provider_cache"
 	namespace        = "newrelic_adapter"
+
+	// otherMetricLabel replaces names of requested metrics not supported by the external provider,
+	// so the cardinality of metric label stays bounded.
+	otherMetricLabel = "other"
+
+	// Results of cache requests.
+	cacheResultHit         = "hit"
+	cacheResultMiss        = "miss"
+	cacheResultNegativeHit = "negative_hit"
 )
 
+//nolint:gochecknoglobals // Slices cannot be constants.
+var ageBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600}
+
 type cacheMetrics struct {
-	size         *metrics.Gauge
-	requestTotal *metrics.CounterVec
+	size            *metrics.Gauge
+	requestTotal    *metrics.CounterVec
+	servedSampleAge *metrics.Histogram
+	servedFetchAge  *metrics.Histogram
+	// backendCallsAvoided counts requests answered with a cached error instead of calling the external provider.
+	backendCallsAvoided *metrics.CounterVec
 }
 
 func getMetrics() cacheMetrics {
@@ -37,7 +53,33 @@ func getMetrics() cacheMetrics {
 				Subsystem:      MetricsSubsystem,
 				Name:           "requests_total",
 				StabilityLevel: metrics.ALPHA,
-			}, []string{"result"}),
+			}, []string{"metric", "result"}),
+		servedSampleAge: metrics.NewHistogram(
+			&metrics.HistogramOpts{
+				Help:           "Age in seconds of the sample timestamp of served external metric values.",
+				Namespace:      namespace,
+				Subsystem:      MetricsSubsystem,
+				Name:           "served_sample_age_seconds",
+				Buckets:        ageBuckets,
+				StabilityLevel: metrics.ALPHA,
+			}),
+		servedFetchAge: metrics.NewHistogram(
+			&metrics.HistogramOpts{
+				Help:           "Time in seconds since served external metric values were fetched from the external provider.",
+				Namespace:      namespace,
+				Subsystem:      MetricsSubsystem,
+				Name:           "served_fetch_age_seconds",
+				Buckets:        ageBuckets,
+				StabilityLevel: metrics.ALPHA,
+			}),
+		backendCallsAvoided: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of calls to the external provider avoided by returning a cached error.",
+				Namespace:      namespace,
+				Subsystem:      MetricsSubsystem,
+				Name:           "backend_calls_avoided_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"class"}),
 	}
 }
 
@@ -49,6 +91,9 @@ func registerMetrics(registerFunc func(metrics.Registerable) error, cacheMetrics
 	for i, metric := range []metrics.Registerable{
 		cacheMetrics.size,
 		cacheMetrics.requestTotal,
+		cacheMetrics.servedSampleAge,
+		cacheMetrics.servedFetchAge,
+		cacheMetrics.backendCallsAvoided,
 	} {
 		if err := registerFunc(metric); err != nil {
 			return fmt.Errorf("registering metric %d: %w", i, err)
@@ -8,43 +8,131 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/component-base/metrics"
 	"k8s.io/klog/v2"
 	"k8s.io/metrics/pkg/apis/external_metrics"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/cacheresult"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/apistatus"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/tracing"
+)
+
+// TTLBasis defines which point in time the cache TTL is counted from.
+type TTLBasis string
+
+const (
+	// TTLBasisSampleTimestamp counts the TTL from the timestamp of the sample returned by the external provider.
+	// This is the default.
+	TTLBasisSampleTimestamp TTLBasis = "sampleTimestamp"
+
+	// TTLBasisFetchTime counts the TTL from the moment the value was fetched from the external provider.
+	TTLBasisFetchTime TTLBasis = "fetchTime"
+)
+
+const (
+	// How often to check if the entry refreshed by another lock holder is already available. Storages serve
+	// loads from memory, so polling does not load the backing store.
+	refreshPollInterval = 100 * time.Millisecond
+
+	// debug level for klog.
+	debug = klog.Level(2)
+
+	tracerName = "github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	// cacheResultKey is the attribute of spans holding the result of the cache lookup.
+	cacheResultKey = attribute.Key("cache.result")
 )
 
 // ProviderOptions holds the configOptions of the provider.
 type ProviderOptions struct {
 	ExternalProvider provider.ExternalMetricsProvider
 	CacheTTLSeconds  int64
-	RegisterFunc     func(metrics.Registerable) error
+	TTLBasis         TTLBasis
+	// Storage holds the cached entries. If nil, entries are kept in memory.
+	Storage Storage
+	// ErrorBackoffSeconds is the period for which errors returned by the external provider are cached.
+	// The period doubles with each consecutive failure for the same metric and selector, up to
+	// MaxErrorBackoffSeconds. Zero or negative value disables caching of errors.
+	ErrorBackoffSeconds    int64
+	MaxErrorBackoffSeconds int64
+	// RefreshWait is how long to wait for the entry refreshed by another lock holder, before querying the
+	// external provider. It should not be shorter than the lock TTL of the storage, so requests do not query
+	// the external provider while the refresh is still in progress. Defaults to DefaultLockTTL.
+	RefreshWait time.Duration
+	// TracerProvider provides the tracer recording spans of requests. If nil, no spans are recorded.
+	TracerProvider trace.TracerProvider
+	// RedactTraces omits selectors and error messages, which may hold the query, from spans of requests.
+	RedactTraces bool
+	RegisterFunc func(metrics.Registerable) error
+	// Clock tells the time of cached entries. Defaults to the real clock.
+	Clock clock.PassiveClock
 }
 
 type cacheProvider struct {
 	externalProvider provider.ExternalMetricsProvider
 	ttlWindow        time.Duration
-	storage          *sync.Map
-	cacheMetrics     cacheMetrics
-}
-
-type cacheEntry struct {
-	value     *external_metrics.ExternalMetricValueList
-	timestamp metav1.Time
+	ttlBasis         TTLBasis
+	storage          Storage
+	errorBackoff     time.Duration
+	maxErrorBackoff  time.Duration
+	refreshWait      time.Duration
+	// hits counts cache hits per key served by this replica.
+	hits         *sync.Map
+	cacheMetrics cacheMetrics
+	tracer       trace.Tracer
+	redactTraces bool
+	clock        clock.PassiveClock
 }
 
 // NewCacheProvider is the constructor for the cache provider.
 func NewCacheProvider(options ProviderOptions) (provider.ExternalMetricsProvider, error) {
 	if options.CacheTTLSeconds <= 0 {
-		klog.Infof("Cache TTL is <= 0. Cache disabled.")
+		klog.InfoS("Cache TTL is <= 0. Cache disabled.")
 
 		return options.ExternalProvider, nil
 	}
 
+	ttlBasis := options.TTLBasis
+	if ttlBasis == "" {
+		ttlBasis = TTLBasisSampleTimestamp
+	}
+
+	if ttlBasis != TTLBasisSampleTimestamp && ttlBasis != TTLBasisFetchTime {
+		return nil, fmt.Errorf("unsupported TTL basis %q, expected %q or %q",
+			ttlBasis, TTLBasisSampleTimestamp, TTLBasisFetchTime)
+	}
+
+	storage := options.Storage
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+
+	errorBackoff := time.Duration(options.ErrorBackoffSeconds) * time.Second
+
+	maxErrorBackoff := time.Duration(options.MaxErrorBackoffSeconds) * time.Second
+	if maxErrorBackoff < errorBackoff {
+		maxErrorBackoff = errorBackoff
+	}
+
+	refreshWait := options.RefreshWait
+	if refreshWait <= 0 {
+		refreshWait = DefaultLockTTL
+	}
+
+	passiveClock := options.Clock
+	if passiveClock == nil {
+		passiveClock = clock.RealClock{}
+	}
+
 	cacheMetrics := getMetrics()
 
 	if err := registerMetrics(options.RegisterFunc, cacheMetrics); err != nil {
@@ -54,8 +142,16 @@ func NewCacheProvider(options ProviderOptions) (provider.ExternalMetricsProvider
 	return &cacheProvider{
 		externalProvider: options.ExternalProvider,
 		ttlWindow:        time.Duration(options.CacheTTLSeconds) * time.Second,
-		storage:          &sync.Map{},
+		ttlBasis:         ttlBasis,
+		storage:          storage,
+		errorBackoff:     errorBackoff,
+		maxErrorBackoff:  maxErrorBackoff,
+		refreshWait:      refreshWait,
+		hits:             &sync.Map{},
 		cacheMetrics:     cacheMetrics,
+		tracer:           tracing.Tracer(options.TracerProvider, tracerName),
+		redactTraces:     options.RedactTraces,
+		clock:            passiveClock,
 	}, nil
 }
 
@@ -65,42 +161,199 @@ func (p *cacheProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
 }
 
 // GetExternalMetric returns the requested metric.
-func (p *cacheProvider) GetExternalMetric(ctx context.Context, _ string, match labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+func (p *cacheProvider) GetExternalMetric(ctx context.Context, namespace string, match labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+	ctx, span := p.tracer.Start(ctx, "CacheProvider.GetExternalMetric",
+		tracing.RequestAttributes(info.Metric, match, p.redactTraces))
+
+	start := time.Now()
+
+	v, result, err := p.getExternalMetric(ctx, match, info)
+
+	span.SetAttributes(cacheResultKey.String(result))
+	tracing.End(span, err, p.redactTraces)
+	cacheresult.Record(ctx, result)
+
+	klog.V(debug).InfoS("Served external metric", logkeys.Metric, info.Metric, logkeys.Selector, selectorString(match),
+		logkeys.Namespace, namespace, logkeys.CacheResult, result, logkeys.Duration, time.Since(start), logkeys.Error, err)
+
+	return v, err
+}
+
+// getExternalMetric returns the requested metric together with the result of the cache lookup.
+func (p *cacheProvider) getExternalMetric(
+	ctx context.Context, match labels.Selector, info provider.ExternalMetricInfo,
+) (*external_metrics.ExternalMetricValueList, string, error) {
 	id := getID(info.Metric, match)
 
-	value, cacheEntryExists := p.storage.Load(id)
+	entry, ok := p.load(ctx, id)
+	if ok && p.isFresh(entry) {
+		return p.hit(id, entry), cacheResultHit, nil
+	}
+
+	if failure := p.backingOff(entry, info.Metric); failure != nil {
+		return nil, cacheResultNegativeHit, failure.Err()
+	}
+
+	unlock, locked, err := p.storage.TryLock(ctx, id)
+	if err != nil {
+		// Rather query the external provider than fail when the storage is not available.
+		klog.ErrorS(err, "Locking cache entry for refresh", logkeys.Key, id)
+	}
+
+	if locked {
+		defer unlock()
+	}
 
-	if cacheEntryExists {
-		c := value.(*cacheEntry) //nolint:forcetypeassert // Cache should always be of this type.
-		if !p.isDataTooOld(c.timestamp) {
-			p.cacheMetrics.requestTotal.WithLabelValues("hit").Inc()
+	if err == nil && !locked {
+		if refreshed, ok := p.waitForRefresh(ctx, id); ok {
+			if failure := p.backingOff(refreshed, info.Metric); failure != nil {
+				return nil, cacheResultNegativeHit, failure.Err()
+			}
 
-			return c.value, nil
+			return p.hit(id, refreshed), cacheResultHit, nil
 		}
 	}
 
-	p.cacheMetrics.requestTotal.WithLabelValues("miss").Inc()
+	p.cacheMetrics.requestTotal.WithLabelValues(p.metricLabel(info.Metric), cacheResultMiss).Inc()
 
 	v, err := p.externalProvider.GetExternalMetric(ctx, "", match, info)
 	if err != nil {
-		return nil, fmt.Errorf("getting fresh external metric value: %w", err)
+		err = apistatus.Preserve(fmt.Errorf("getting fresh external metric value: %w", err))
+		p.recordFailure(ctx, id, entry, match, info.Metric, err)
+
+		return nil, cacheResultMiss, err
 	}
 
 	if l := len(v.Items); l != 1 {
-		return nil, fmt.Errorf("expected exactly 1 metric from external provider for metric %q, got %d", id, l)
+		return nil, cacheResultMiss, apierrors.NewInternalError(
+			fmt.Errorf("expected exactly 1 metric from external provider for metric %q, got %d", id, l),
+		)
 	}
 
-	// Only new entries will increase the storage size.
-	if !cacheEntryExists {
-		p.cacheMetrics.size.Inc()
+	entry = &Entry{
+		Metric:    info.Metric,
+		Selector:  selectorString(match),
+		Value:     v,
+		Timestamp: v.Items[0].Timestamp,
+		FetchedAt: p.clock.Now(),
 	}
 
-	p.storage.Store(id, &cacheEntry{
-		value:     v,
-		timestamp: v.Items[0].Timestamp,
+	if err := p.storage.Store(ctx, id, entry); err != nil {
+		klog.ErrorS(err, "Storing cache entry", logkeys.Key, id)
+	}
+
+	p.updateSize(ctx)
+	p.observeServedAge(entry)
+
+	return v, cacheResultMiss, nil
+}
+
+// metricSupporter is implemented by external providers which can tell whether they support a metric without
+// listing all supported metrics.
+type metricSupporter interface {
+	Supports(metric string) bool
+}
+
+// metricLabel returns the value of metric label for the given metric name. The list of supported metrics
+// is not cached, as the external provider may change it, so providers which cannot tell whether they support
+// the metric directly are asked for the list on every request.
+func (p *cacheProvider) metricLabel(metric string) string {
+	if supporter, ok := p.externalProvider.(metricSupporter); ok {
+		if supporter.Supports(metric) {
+			return metric
+		}
+
+		return otherMetricLabel
+	}
+
+	for _, info := range p.externalProvider.ListAllExternalMetrics() {
+		if info.Metric == metric {
+			return metric
+		}
+	}
+
+	return otherMetricLabel
+}
+
+func (p *cacheProvider) hit(id string, entry *Entry) *external_metrics.ExternalMetricValueList {
+	hits, _ := p.hits.LoadOrStore(id, new(int64))
+	atomic.AddInt64(hits.(*int64), 1) //nolint:forcetypeassert // Hits should always be of this type.
+
+	p.cacheMetrics.requestTotal.WithLabelValues(p.metricLabel(entry.Metric), cacheResultHit).Inc()
+	p.observeServedAge(entry)
+
+	return entry.Value
+}
+
+// load returns the entry for the given key. Storage errors are treated as a missing entry, as the value
+// can still be fetched from the external provider.
+func (p *cacheProvider) load(ctx context.Context, id string) (*Entry, bool) {
+	entry, ok, err := p.storage.Load(ctx, id)
+	if err != nil {
+		klog.ErrorS(err, "Loading cache entry", logkeys.Key, id)
+
+		return nil, false
+	}
+
+	return entry, ok
+}
+
+// updateSize sets the size metric to the number of values in the storage. The storage may be shared with other
+// replicas, so entries added or removed by this replica alone do not tell the size. Hit counters of entries
+// no longer stored, e.g. evicted by the storage or deleted by other replicas, are dropped as well.
+func (p *cacheProvider) updateSize(ctx context.Context) {
+	entries, err := p.storage.List(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Listing cache entries to update size")
+
+		return
+	}
+
+	p.hits.Range(func(id, _ interface{}) bool {
+		if _, ok := entries[id.(string)]; !ok { //nolint:forcetypeassert // Hits should always be keyed by string.
+			p.hits.Delete(id)
+		}
+
+		return true
 	})
 
-	return v, nil
+	size := 0
+
+	for _, entry := range entries {
+		if entry.Value != nil {
+			size++
+		}
+	}
+
+	p.cacheMetrics.size.Set(float64(size))
+}
+
+// waitForRefresh waits until the entry being refreshed by another lock holder becomes fresh or its refresh fails.
+func (p *cacheProvider) waitForRefresh(ctx context.Context, id string) (*Entry, bool) {
+	ctx, cancel := context.WithTimeout(ctx, p.refreshWait)
+	defer cancel()
+
+	start := p.clock.Now()
+
+	ticker := time.NewTicker(refreshPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-ticker.C:
+		}
+
+		entry, ok := p.load(ctx, id)
+		if !ok {
+			continue
+		}
+
+		if p.isFresh(entry) || (entry.Failure != nil && !entry.Failure.FailedAt.Before(start)) {
+			return entry, true
+		}
+	}
 }
 
 func getID(metricName string, selector labels.Selector) string {
@@ -112,8 +365,32 @@ func getID(metricName string, selector labels.Selector) string {
 	return id
 }
 
-func (p *cacheProvider) isDataTooOld(timestamp metav1.Time) bool {
-	oldestSampleAllowed := time.Now().Add(-p.ttlWindow)
+func selectorString(selector labels.Selector) string {
+	if selector == nil {
+		return ""
+	}
+
+	return selector.String()
+}
+
+// isFresh returns whether the entry holds a value which can be served.
+func (p *cacheProvider) isFresh(entry *Entry) bool {
+	return entry.Value != nil && !p.isEntryTooOld(entry)
+}
+
+func (p *cacheProvider) isEntryTooOld(entry *Entry) bool {
+	oldestAllowed := p.clock.Now().Add(-p.ttlWindow)
+
+	if p.ttlBasis == TTLBasisFetchTime {
+		return !entry.FetchedAt.After(oldestAllowed)
+	}
+
+	return !entry.Timestamp.After(oldestAllowed)
+}
 
-	return !timestamp.After(oldestSampleAllowed)
+// observeServedAge records how old the served sample is and how long ago it was fetched, so freshness of the data
+// and the volume of queries sent to the external provider can be reasoned about separately.
+func (p *cacheProvider) observeServedAge(entry *Entry) {
+	p.cacheMetrics.servedSampleAge.Observe(p.clock.Since(entry.Timestamp.Time).Seconds())
+	p.cacheMetrics.servedFetchAge.Observe(p.clock.Since(entry.FetchedAt).Seconds())
 }
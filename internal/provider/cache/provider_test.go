@@ -7,19 +7,26 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/component-base/metrics"
 	metricsTestutil "k8s.io/component-base/metrics/testutil"
 	"k8s.io/metrics/pkg/apis/external_metrics"
+	testingclock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
 
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
@@ -144,6 +151,26 @@ func Test_Getting_external_metric_returns(t *testing.T) {
 		}
 	})
 
+	t.Run("error_with_API_status_of_error_returned_by_the_external_provider", func(t *testing.T) {
+		t.Parallel()
+
+		mockProvider := &mock.Provider{
+			GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+				return nil, apierrors.NewServiceUnavailable("backend is down")
+			},
+		}
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{ExternalProvider: mockProvider, CacheTTLSeconds: 5})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		_, err = p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne})
+		if code := responsewriters.ErrorToAPIStatus(err).Code; code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected API status code %d, got %d: %v", http.StatusServiceUnavailable, code, err)
+		}
+	})
+
 	t.Run("cached_value_when", func(t *testing.T) {
 		t.Parallel()
 
@@ -295,7 +322,7 @@ newrelic_adapter_external_provider_cache_size 1
 		expectedMetric := bytes.NewBufferString(`
 # HELP newrelic_adapter_external_provider_cache_requests_total [ALPHA] Total number of cache request.
 # TYPE newrelic_adapter_external_provider_cache_requests_total counter
-newrelic_adapter_external_provider_cache_requests_total{result="miss"} 2
+newrelic_adapter_external_provider_cache_requests_total{metric="mock_metric",result="miss"} 2
 `)
 
 		if err := metricsTestutil.GatherAndCompare(
@@ -326,8 +353,58 @@ func Test_Getting_cached_external_metric_increments_cache_hit_metric(t *testing.
 	expectedMetric := bytes.NewBufferString(`
 # HELP newrelic_adapter_external_provider_cache_requests_total [ALPHA] Total number of cache request.
 # TYPE newrelic_adapter_external_provider_cache_requests_total counter
-newrelic_adapter_external_provider_cache_requests_total{result="miss"} 1
-newrelic_adapter_external_provider_cache_requests_total{result="hit"} 1
+newrelic_adapter_external_provider_cache_requests_total{metric="mock_metric",result="miss"} 1
+newrelic_adapter_external_provider_cache_requests_total{metric="mock_metric",result="hit"} 1
+`)
+
+	if err := metricsTestutil.GatherAndCompare(
+		registry,
+		expectedMetric,
+		"newrelic_adapter_external_provider_cache_requests_total",
+	); err != nil {
+		t.Fatalf("Unexpected error while gathering cache metrics: %v", err)
+	}
+}
+
+func Test_Getting_external_metric_labels_cache_request_metric_without_listing_metrics_of_supporting_provider(t *testing.T) { //nolint:lll // Just a long test name.
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	registry := metrics.NewKubeRegistry()
+
+	options := cache.ProviderOptions{
+		ExternalProvider: &supportingProvider{
+			Provider: &mock.Provider{
+				ListAllExternalMetricsFunc: func() []provider.ExternalMetricInfo {
+					t.Errorf("Unexpected listing of external metrics")
+
+					return nil
+				},
+			},
+			supported: "mock_metric",
+		},
+		CacheTTLSeconds: 30,
+		RegisterFunc:    registry.Register,
+	}
+
+	p, err := cache.NewCacheProvider(options)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	for _, metric := range []string{"mock_metric", "mock_metric", "unknown_metric"} {
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: metric}); err != nil {
+			t.Fatalf("Unexpected error while getting external metric: %v", err)
+		}
+	}
+
+	expectedMetric := bytes.NewBufferString(`
+# HELP newrelic_adapter_external_provider_cache_requests_total [ALPHA] Total number of cache request.
+# TYPE newrelic_adapter_external_provider_cache_requests_total counter
+newrelic_adapter_external_provider_cache_requests_total{metric="mock_metric",result="miss"} 1
+newrelic_adapter_external_provider_cache_requests_total{metric="mock_metric",result="hit"} 1
+newrelic_adapter_external_provider_cache_requests_total{metric="other",result="miss"} 1
 `)
 
 	if err := metricsTestutil.GatherAndCompare(
@@ -339,6 +416,374 @@ newrelic_adapter_external_provider_cache_requests_total{result="hit"} 1
 	}
 }
 
+func Test_Getting_external_metric_records_span_with_result_of_cache_lookup(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	recorder := tracetest.NewSpanRecorder()
+
+	options := cache.ProviderOptions{
+		ExternalProvider: &mock.Provider{},
+		CacheTTLSeconds:  30,
+		TracerProvider:   sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	}
+
+	p, err := cache.NewCacheProvider(options)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: "mock_metric"}); err != nil {
+			t.Fatalf("Unexpected error while getting external metric: %v", err)
+		}
+	}
+
+	results := []string{}
+
+	for _, span := range recorder.Ended() {
+		for _, kv := range span.Attributes() {
+			if kv.Key == "cache.result" {
+				results = append(results, kv.Value.AsString())
+			}
+		}
+	}
+
+	if diff := cmp.Diff([]string{"miss", "hit"}, results); diff != "" {
+		t.Fatalf("Unexpected cache results recorded in spans (-want +got):\n%s", diff)
+	}
+}
+
+func Test_Getting_external_metric_records_span_without_selector_and_error_message_when_redacting(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	recorder := tracetest.NewSpanRecorder()
+
+	options := cache.ProviderOptions{
+		ExternalProvider: &mock.Provider{
+			GetExternalMetricFunc: func(
+				context.Context, string, labels.Selector, provider.ExternalMetricInfo,
+			) (*external_metrics.ExternalMetricValueList, error) {
+				return nil, fmt.Errorf("query %q failed", "select secret from Sample")
+			},
+		},
+		CacheTTLSeconds: 30,
+		TracerProvider:  sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		RedactTraces:    true,
+	}
+
+	p, err := cache.NewCacheProvider(options)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	sl, err := labels.Parse("app=secret")
+	if err != nil {
+		t.Fatalf("Parsing selector: %v", err)
+	}
+
+	if _, err := p.GetExternalMetric(ctx, "", sl, provider.ExternalMetricInfo{Metric: "mock_metric"}); err == nil {
+		t.Fatalf("Expected error")
+	}
+
+	for _, span := range recorder.Ended() {
+		recorded := []string{span.Status().Description}
+
+		for _, kv := range span.Attributes() {
+			recorded = append(recorded, kv.Value.Emit())
+		}
+
+		for _, event := range span.Events() {
+			for _, kv := range event.Attributes {
+				recorded = append(recorded, kv.Value.Emit())
+			}
+		}
+
+		for _, value := range recorded {
+			if strings.Contains(value, "secret") {
+				t.Errorf("Expected span %q to not contain query or selector, got %q", span.Name(), value)
+			}
+		}
+	}
+}
+
+func Test_Getting_external_metric_not_supported_by_external_provider_increments_cache_request_metric_for_other_metric(t *testing.T) { //nolint:lll // Just a long test name.
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	p, _, registry := getTestCacheProvider(t, 1)
+
+	if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: "unknown_metric"}); err != nil {
+		t.Fatalf("Unexpected error while getting external metric: %v", err)
+	}
+
+	expectedMetric := bytes.NewBufferString(`
+# HELP newrelic_adapter_external_provider_cache_requests_total [ALPHA] Total number of cache request.
+# TYPE newrelic_adapter_external_provider_cache_requests_total counter
+newrelic_adapter_external_provider_cache_requests_total{metric="other",result="miss"} 1
+`)
+
+	if err := metricsTestutil.GatherAndCompare(
+		registry,
+		expectedMetric,
+		"newrelic_adapter_external_provider_cache_requests_total",
+	); err != nil {
+		t.Fatalf("Unexpected error while gathering cache metrics: %v", err)
+	}
+}
+
+func Test_Getting_external_metric_with_sample_older_than_TTL_returns(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	cases := map[string]struct {
+		ttlBasis      cache.TTLBasis
+		expectedCalls int
+	}{
+		"fresh_value_when_TTL_is_based_on_sample_timestamp": {
+			ttlBasis:      cache.TTLBasisSampleTimestamp,
+			expectedCalls: 2,
+		},
+		"fresh_value_when_TTL_basis_is_not_configured": {
+			expectedCalls: 2,
+		},
+		"cached_value_when_TTL_is_based_on_fetch_time": {
+			ttlBasis:      cache.TTLBasisFetchTime,
+			expectedCalls: 1,
+		},
+	}
+
+	for testCaseName, testData := range cases {
+		testData := testData
+
+		t.Run(testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			numCalls := 0
+
+			mockProvider := &mock.Provider{
+				GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+					numCalls++
+
+					return &external_metrics.ExternalMetricValueList{
+						Items: []external_metrics.ExternalMetricValue{
+							{Timestamp: metav1.NewTime(time.Now().Add(-time.Hour)), Value: resource.Quantity{}},
+						},
+					}, nil
+				},
+			}
+
+			p, err := cache.NewCacheProvider(cache.ProviderOptions{
+				ExternalProvider: mockProvider,
+				CacheTTLSeconds:  30,
+				TTLBasis:         testData.ttlBasis,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error creating the provider: %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne}); err != nil {
+					t.Fatalf("Unexpected error while getting external metric: %v", err)
+				}
+			}
+
+			if numCalls != testData.expectedCalls {
+				t.Errorf("Expected exactly %d calls to backend, got %d", testData.expectedCalls, numCalls)
+			}
+		})
+	}
+}
+
+//nolint:funlen // Just a large test suite.
+func Test_Getting_external_metric_when_external_provider_fails(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	failingProvider := func(numCalls *int) *mock.Provider {
+		return &mock.Provider{
+			GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+				*numCalls++
+
+				return nil, testClassifiedError{class: "rate_limit"}
+			},
+		}
+	}
+
+	t.Run("returns_original_error_without_calling_external_provider_during_backoff", func(t *testing.T) {
+		t.Parallel()
+
+		numCalls := 0
+		registry := metrics.NewKubeRegistry()
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{
+			ExternalProvider:    failingProvider(&numCalls),
+			CacheTTLSeconds:     30,
+			ErrorBackoffSeconds: 30,
+			RegisterFunc:        registry.Register,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		_, firstErr := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne})
+		if firstErr == nil {
+			t.Fatalf("Expected error")
+		}
+
+		for i := 0; i < 3; i++ {
+			_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne})
+			if err != firstErr { //nolint:errorlint // We want exactly the same error.
+				t.Fatalf("Expected error %v, got %v", firstErr, err)
+			}
+		}
+
+		if numCalls != 1 {
+			t.Fatalf("Expected exactly 1 call to backend, got %d", numCalls)
+		}
+
+		expectedMetric := bytes.NewBufferString(`
+# HELP newrelic_adapter_external_provider_cache_backend_calls_avoided_total [ALPHA] Total number of calls to the external provider avoided by returning a cached error.
+# TYPE newrelic_adapter_external_provider_cache_backend_calls_avoided_total counter
+newrelic_adapter_external_provider_cache_backend_calls_avoided_total{class="rate_limit"} 3
+`)
+
+		if err := metricsTestutil.GatherAndCompare(
+			registry,
+			expectedMetric,
+			"newrelic_adapter_external_provider_cache_backend_calls_avoided_total",
+		); err != nil {
+			t.Fatalf("Unexpected error while gathering cache metrics: %v", err)
+		}
+	})
+
+	t.Run("calls_external_provider_again_when_backoff_expires", func(t *testing.T) {
+		t.Parallel()
+
+		numCalls := 0
+		fakeClock := testingclock.NewFakeClock(time.Now())
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{
+			ExternalProvider:       failingProvider(&numCalls),
+			CacheTTLSeconds:        30,
+			ErrorBackoffSeconds:    1,
+			MaxErrorBackoffSeconds: 60,
+			Clock:                  fakeClock,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		// Backoff doubles with each consecutive failure.
+		for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			callsBefore := numCalls
+
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne}); err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			fakeClock.Step(backoff - time.Millisecond)
+
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne}); err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if calls := numCalls - callsBefore; calls != 1 {
+				t.Fatalf("Expected 1 call to backend within backoff of %v, got %d", backoff, calls)
+			}
+
+			fakeClock.Step(time.Millisecond)
+		}
+	})
+
+	t.Run("shares_cached_error_with_replicas_using_the_same_storage", func(t *testing.T) {
+		t.Parallel()
+
+		numCalls := 0
+		client := fake.NewClientset()
+
+		providers := []provider.ExternalMetricsProvider{}
+		storages := []cache.Storage{}
+
+		for _, holder := range []string{"first", "second"} {
+			storage := testConfigMapStorage(t, client, cache.ConfigMapStorageOptions{Holder: holder})
+
+			p, err := cache.NewCacheProvider(cache.ProviderOptions{
+				ExternalProvider:    failingProvider(&numCalls),
+				CacheTTLSeconds:     30,
+				ErrorBackoffSeconds: 30,
+				Storage:             storage,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error creating the provider: %v", err)
+			}
+
+			providers = append(providers, p)
+			storages = append(storages, storage)
+		}
+
+		info := provider.ExternalMetricInfo{Metric: testMetricNameOne}
+
+		if _, err := providers[0].GetExternalMetric(ctx, "", nil, info); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		waitForEntry(ctx, t, storages[1], testMetricNameOne)
+
+		if _, err := providers[1].GetExternalMetric(ctx, "", nil, info); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if numCalls != 1 {
+			t.Fatalf("Expected exactly 1 call to backend, got %d", numCalls)
+		}
+	})
+
+	t.Run("calls_external_provider_every_time_when_error_backoff_is_disabled", func(t *testing.T) {
+		t.Parallel()
+
+		numCalls := 0
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{
+			ExternalProvider: failingProvider(&numCalls),
+			CacheTTLSeconds:  30,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricNameOne}); err == nil {
+				t.Fatalf("Expected error")
+			}
+		}
+
+		if numCalls != 3 {
+			t.Fatalf("Expected exactly 3 calls to backend, got %d", numCalls)
+		}
+	})
+}
+
+func Test_Creating_provider_returns_error_when_TTL_basis_is_not_supported(t *testing.T) {
+	t.Parallel()
+
+	options := cache.ProviderOptions{
+		ExternalProvider: &mock.Provider{},
+		CacheTTLSeconds:  1,
+		TTLBasis:         "foo",
+	}
+
+	if _, err := cache.NewCacheProvider(options); err == nil {
+		t.Fatalf("Expected error creating the provider")
+	}
+}
+
 func Test_Creating_provider_returns_error_when_registering_metrics_fails(t *testing.T) {
 	t.Parallel()
 
@@ -381,6 +826,9 @@ func getTestCacheProvider(t *testing.T, cacheTTL int64) (provider.ExternalMetric
 				},
 			}, nil
 		},
+		ListAllExternalMetricsFunc: func() []provider.ExternalMetricInfo {
+			return []provider.ExternalMetricInfo{{Metric: "mock_metric"}}
+		},
 	}
 
 	registry := metrics.NewKubeRegistry()
@@ -399,6 +847,28 @@ func getTestCacheProvider(t *testing.T, cacheTTL int64) (provider.ExternalMetric
 	return p, &numCalls, registry
 }
 
+// supportingProvider tells whether it supports a metric without listing all metrics.
+type supportingProvider struct {
+	*mock.Provider
+	supported string
+}
+
+func (p *supportingProvider) Supports(metric string) bool {
+	return metric == p.supported
+}
+
+type testClassifiedError struct {
+	class string
+}
+
+func (e testClassifiedError) Error() string {
+	return "classified error"
+}
+
+func (e testClassifiedError) ErrorClass() string {
+	return e.class
+}
+
 type testDataStruct struct {
 	cacheTTLSeconds      int64
 	secondsToSleep       time.Duration
@@ -0,0 +1,164 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+const (
+	// How often the memory storage removes entries not updated within DefaultMaxEntryAge.
+	memoryEvictionInterval = time.Minute
+)
+
+// Entry is a single external metric value stored in the cache.
+type Entry struct {
+	Metric    string                                    `json:"metric"`
+	Selector  string                                    `json:"selector"`
+	Value     *external_metrics.ExternalMetricValueList `json:"value"`
+	Timestamp metav1.Time                               `json:"timestamp"`
+	FetchedAt time.Time                                 `json:"fetchedAt"`
+	// Failure is set when the latest refresh of the entry failed. Value then holds the previously fetched
+	// value, if there is any.
+	Failure *Failure `json:"failure,omitempty"`
+}
+
+// Failure is the error returned by the external provider when refreshing an entry. It is cached, so all
+// replicas sharing the storage back off.
+type Failure struct {
+	// Status is the API status of the error, as returned to the client.
+	Status metav1.Status `json:"status"`
+	Class  string        `json:"class"`
+	// Failures is the number of consecutive failures, which doubles the backoff period with each one.
+	Failures int       `json:"failures"`
+	FailedAt time.Time `json:"failedAt"`
+	Until    time.Time `json:"until"`
+
+	// err is the original error, available when the failure was recorded by this replica.
+	err error
+}
+
+// Err returns the error of the failure.
+func (f *Failure) Err() error {
+	if f.err != nil {
+		return f.err
+	}
+
+	return &apierrors.StatusError{ErrStatus: f.Status}
+}
+
+// updatedAt returns when the entry was last written, i.e. when the value was fetched or refreshing it failed.
+func (e *Entry) updatedAt() time.Time {
+	if e.Failure != nil && e.Failure.FailedAt.After(e.FetchedAt) {
+		return e.Failure.FailedAt
+	}
+
+	return e.FetchedAt
+}
+
+// Storage holds cache entries for the cache provider. Implementations must be safe for concurrent use.
+type Storage interface {
+	// Load returns the entry stored under the given key, if any.
+	Load(ctx context.Context, key string) (*Entry, bool, error)
+
+	// Store saves the entry under the given key, replacing the existing one.
+	Store(ctx context.Context, key string, entry *Entry) error
+
+	// Delete removes the entry stored under the given key.
+	Delete(ctx context.Context, key string) error
+
+	// List returns all stored entries by their keys.
+	List(ctx context.Context) (map[string]*Entry, error)
+
+	// TryLock attempts to acquire the exclusive right to refresh the given key. If the lock is acquired,
+	// returned function must be called to release it.
+	TryLock(ctx context.Context, key string) (func(), bool, error)
+}
+
+type memoryStorage struct {
+	entries *sync.Map
+	locks   *sync.Map
+
+	mu        sync.Mutex
+	lastEvict time.Time
+}
+
+// NewMemoryStorage returns storage keeping entries in memory of the current process. Entries not updated
+// within DefaultMaxEntryAge are removed, so entries of selectors no longer requested do not accumulate.
+func NewMemoryStorage() Storage {
+	return &memoryStorage{
+		entries: &sync.Map{},
+		locks:   &sync.Map{},
+	}
+}
+
+func (s *memoryStorage) Load(_ context.Context, key string) (*Entry, bool, error) {
+	value, ok := s.entries.Load(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	return value.(*Entry), true, nil //nolint:forcetypeassert // Storage should always hold this type.
+}
+
+func (s *memoryStorage) Store(_ context.Context, key string, entry *Entry) error {
+	s.entries.Store(key, entry)
+
+	s.evict(time.Now())
+
+	return nil
+}
+
+// evict removes entries not updated within DefaultMaxEntryAge, at most once per eviction interval.
+func (s *memoryStorage) evict(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastEvict) < memoryEvictionInterval {
+		return
+	}
+
+	s.lastEvict = now
+
+	s.entries.Range(func(key, value interface{}) bool {
+		entry := value.(*Entry) //nolint:forcetypeassert // Storage should always hold this type.
+		if now.Sub(entry.updatedAt()) > DefaultMaxEntryAge {
+			s.entries.Delete(key)
+		}
+
+		return true
+	})
+}
+
+func (s *memoryStorage) Delete(_ context.Context, key string) error {
+	s.entries.Delete(key)
+
+	return nil
+}
+
+func (s *memoryStorage) List(_ context.Context) (map[string]*Entry, error) {
+	entries := map[string]*Entry{}
+
+	s.entries.Range(func(key, value interface{}) bool {
+		entries[key.(string)] = value.(*Entry) //nolint:forcetypeassert // Storage should always hold these types.
+
+		return true
+	})
+
+	return entries, nil
+}
+
+func (s *memoryStorage) TryLock(_ context.Context, key string) (func(), bool, error) {
+	if _, locked := s.locks.LoadOrStore(key, struct{}{}); locked {
+		return nil, false, nil
+	}
+
+	return func() { s.locks.Delete(key) }, true, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+func Test_Memory_storage_evicts_entries_not_updated_within_max_entry_age(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+	s := cache.NewMemoryStorage()
+
+	if err := s.Store(ctx, "old", testEntry(time.Now().Add(-2*cache.DefaultMaxEntryAge))); err != nil {
+		t.Fatalf("Unexpected error storing entry: %v", err)
+	}
+
+	if err := s.Store(ctx, "new", testEntry(time.Now())); err != nil {
+		t.Fatalf("Unexpected error storing entry: %v", err)
+	}
+
+	entries, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error listing entries: %v", err)
+	}
+
+	if _, ok := entries["old"]; ok || len(entries) != 1 {
+		t.Fatalf("Expected only fresh entry to be kept, got %v", entries)
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultWarmUpTimeout is the time after which warm-up is considered finished, even if not all keys
+	// have been warmed up yet.
+	DefaultWarmUpTimeout = 60 * time.Second
+
+	// How many keys are warmed up concurrently.
+	warmUpConcurrency = 4
+	// How often selectors of cached entries are persisted to the selectors file.
+	selectorsPersistInterval = time.Minute
+	// Entries not fetched for longer than this are not considered recently seen and are not warmed up.
+	selectorsRetention = 24 * time.Hour
+)
+
+// WarmUpOptions holds the configuration of the cache warm-up.
+type WarmUpOptions struct {
+	// SelectorsFile is a path to the file where selectors of recently cached entries are persisted,
+	// so they can be warmed up after restart. If empty, selectors are not persisted.
+	SelectorsFile string
+	Timeout       time.Duration
+}
+
+// WarmUp fills the cache with values of all configured metrics before the adapter reports being ready.
+//
+// Each configured metric is queried with an empty selector, along with recently seen selectors found
+// in the cache storage, which survive restarts when shared storage is used, and in the selectors file.
+type WarmUp struct {
+	provider      *cacheProvider
+	selectorsFile string
+	timeout       time.Duration
+
+	total    int64
+	warmedUp int64
+	finished int32
+}
+
+type warmUpKey struct {
+	Metric   string `json:"metric"`
+	Selector string `json:"selector"`
+}
+
+// NewWarmUp returns warm-up for the given provider. If the given provider is not a cache provider, e.g. because
+// caching is disabled, nil is returned.
+func NewWarmUp(p provider.ExternalMetricsProvider, options WarmUpOptions) *WarmUp {
+	cp, ok := p.(*cacheProvider)
+	if !ok {
+		return nil
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWarmUpTimeout
+	}
+
+	return &WarmUp{
+		provider:      cp,
+		selectorsFile: options.SelectorsFile,
+		timeout:       timeout,
+	}
+}
+
+// Name implements healthz.HealthChecker.
+func (w *WarmUp) Name() string {
+	return "cache-warm-up"
+}
+
+// Check implements healthz.HealthChecker. It returns an error until warm-up is finished.
+func (w *WarmUp) Check(_ *http.Request) error {
+	if atomic.LoadInt32(&w.finished) == 1 {
+		return nil
+	}
+
+	return fmt.Errorf("cache warm-up in progress, %d of %d keys warmed up",
+		atomic.LoadInt64(&w.warmedUp), atomic.LoadInt64(&w.total))
+}
+
+// Run warms up the cache and then periodically persists selectors of cached entries until given context is canceled.
+func (w *WarmUp) Run(ctx context.Context) {
+	w.warmUp(ctx)
+
+	if w.selectorsFile == "" {
+		return
+	}
+
+	ticker := time.NewTicker(selectorsPersistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Persist once more, so selectors seen since the last tick are not lost on shutdown.
+			w.persistSelectors(context.Background())
+
+			return
+		case <-ticker.C:
+			w.persistSelectors(ctx)
+		}
+	}
+}
+
+func (w *WarmUp) warmUp(ctx context.Context) {
+	defer atomic.StoreInt32(&w.finished, 1)
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	start := time.Now()
+	keys := w.keys(ctx)
+
+	atomic.StoreInt64(&w.total, int64(len(keys)))
+
+	klog.InfoS("Warming up cache", logkeys.Total, len(keys))
+
+	queue := make(chan warmUpKey)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < warmUpConcurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for key := range queue {
+				w.warmUpKey(ctx, key)
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		queue <- key
+	}
+
+	close(queue)
+	wg.Wait()
+
+	klog.InfoS("Cache warm-up finished", logkeys.Duration, time.Since(start),
+		logkeys.Count, atomic.LoadInt64(&w.warmedUp), logkeys.Total, len(keys))
+}
+
+func (w *WarmUp) warmUpKey(ctx context.Context, key warmUpKey) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	selector, err := labels.Parse(key.Selector)
+	if err != nil {
+		klog.ErrorS(err, "Parsing selector for warm-up", logkeys.Metric, key.Metric, logkeys.Selector, key.Selector)
+
+		return
+	}
+
+	info := provider.ExternalMetricInfo{Metric: key.Metric}
+
+	if _, err := w.provider.GetExternalMetric(ctx, "", selector, info); err != nil {
+		klog.ErrorS(err, "Warming up metric", logkeys.Metric, key.Metric, logkeys.Selector, key.Selector)
+
+		return
+	}
+
+	atomic.AddInt64(&w.warmedUp, 1)
+}
+
+// keys returns keys to warm up, limited to currently configured metrics.
+func (w *WarmUp) keys(ctx context.Context) []warmUpKey {
+	configured := map[string]struct{}{}
+	keys := []warmUpKey{}
+	seen := map[string]struct{}{}
+
+	add := func(key warmUpKey) {
+		if _, ok := configured[key.Metric]; !ok {
+			return
+		}
+
+		id := key.Metric + "/" + key.Selector
+		if _, ok := seen[id]; ok {
+			return
+		}
+
+		seen[id] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	for _, info := range w.provider.ListAllExternalMetrics() {
+		configured[info.Metric] = struct{}{}
+		add(warmUpKey{Metric: info.Metric})
+	}
+
+	stored, err := w.recentlySeen(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Listing recently seen selectors for warm-up")
+	}
+
+	for _, key := range stored {
+		add(key)
+	}
+
+	persisted, err := w.readSelectors()
+	if err != nil {
+		klog.ErrorS(err, "Reading selectors file for warm-up", logkeys.File, w.selectorsFile)
+	}
+
+	for _, key := range persisted {
+		add(key)
+	}
+
+	return keys
+}
+
+// recentlySeen returns keys of entries in the storage, which have been fetched within the retention period.
+func (w *WarmUp) recentlySeen(ctx context.Context) ([]warmUpKey, error) {
+	entries, err := w.provider.storage.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing storage: %w", err)
+	}
+
+	keys := []warmUpKey{}
+
+	for _, entry := range entries {
+		if time.Since(entry.FetchedAt) > selectorsRetention {
+			continue
+		}
+
+		keys = append(keys, warmUpKey{Metric: entry.Metric, Selector: entry.Selector})
+	}
+
+	return keys, nil
+}
+
+func (w *WarmUp) readSelectors() ([]warmUpKey, error) {
+	if w.selectorsFile == "" {
+		return nil, nil
+	}
+
+	raw, err := os.ReadFile(w.selectorsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	keys := []warmUpKey{}
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return nil, fmt.Errorf("unmarshalling selectors: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (w *WarmUp) persistSelectors(ctx context.Context) {
+	keys, err := w.recentlySeen(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Listing selectors to persist")
+
+		return
+	}
+
+	if err := writeSelectors(w.selectorsFile, keys); err != nil {
+		klog.ErrorS(err, "Persisting selectors", logkeys.File, w.selectorsFile)
+	}
+}
+
+// writeSelectors replaces the selectors file atomically, so a crash never leaves a truncated file behind.
+func writeSelectors(path string, keys []warmUpKey) error {
+	raw, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("marshalling selectors: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) //nolint:errcheck // File is already renamed on success.
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("renaming temporary file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Cache_warm_up(t *testing.T) {
+	t.Parallel()
+
+	t.Run("queries_every_configured_metric_with_empty_selector", func(t *testing.T) {
+		t.Parallel()
+
+		mockProvider, queried := recordingMockProvider(nil)
+		warmUp := testWarmUp(t, mockProvider, "")
+
+		runWarmUp(t, warmUp)
+
+		expected := []string{testMetricNameOne + "/", testMetricNameTwo + "/"}
+		if diff := cmp.Diff(expected, queried()); diff != "" {
+			t.Fatalf("Unexpected queried keys (-expected +got):\n%s", diff)
+		}
+	})
+
+	t.Run("queries_selectors_persisted_in_file", func(t *testing.T) {
+		t.Parallel()
+
+		selectorsFile := filepath.Join(t.TempDir(), "selectors.json")
+
+		persisted := `[{"metric":"testMetricOne","selector":"foo=bar"},{"metric":"removedMetric","selector":"foo=bar"}]`
+		if err := os.WriteFile(selectorsFile, []byte(persisted), 0o600); err != nil {
+			t.Fatalf("Writing selectors file: %v", err)
+		}
+
+		mockProvider, queried := recordingMockProvider(nil)
+		warmUp := testWarmUp(t, mockProvider, selectorsFile)
+
+		runWarmUp(t, warmUp)
+
+		expected := []string{testMetricNameOne + "/", testMetricNameOne + "/foo=bar", testMetricNameTwo + "/"}
+		if diff := cmp.Diff(expected, queried()); diff != "" {
+			t.Fatalf("Unexpected queried keys (-expected +got):\n%s", diff)
+		}
+	})
+
+	t.Run("persists_selectors_of_cached_entries_on_shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		selectorsFile := filepath.Join(t.TempDir(), "selectors.json")
+
+		mockProvider, _ := recordingMockProvider(nil)
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{ExternalProvider: mockProvider, CacheTTLSeconds: 30})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		selector := labels.SelectorFromSet(labels.Set{"foo": "bar"})
+		info := provider.ExternalMetricInfo{Metric: testMetricNameOne}
+
+		if _, err := p.GetExternalMetric(testutil.ContextWithDeadline(t), "", selector, info); err != nil {
+			t.Fatalf("Unexpected error while getting external metric: %v", err)
+		}
+
+		warmUp := cache.NewWarmUp(p, cache.WarmUpOptions{SelectorsFile: selectorsFile})
+
+		ctx, cancel := context.WithCancel(testutil.ContextWithDeadline(t))
+		cancel()
+
+		warmUp.Run(ctx)
+
+		raw, err := os.ReadFile(selectorsFile)
+		if err != nil {
+			t.Fatalf("Reading selectors file: %v", err)
+		}
+
+		persisted := []map[string]string{}
+		if err := json.Unmarshal(raw, &persisted); err != nil {
+			t.Fatalf("Decoding selectors file: %v", err)
+		}
+
+		found := false
+
+		for _, key := range persisted {
+			if key["metric"] == testMetricNameOne && key["selector"] == "foo=bar" {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatalf("Expected selector %q of metric %q to be persisted, got %s", "foo=bar", testMetricNameOne, raw)
+		}
+	})
+
+	t.Run("reports_not_ready_until_finished", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+
+		mockProvider, _ := recordingMockProvider(release)
+		warmUp := testWarmUp(t, mockProvider, "")
+
+		ctx := testutil.ContextWithDeadline(t)
+
+		go warmUp.Run(ctx)
+
+		err := warmUp.Check(nil)
+		if err == nil {
+			t.Fatalf("Expected warm-up to report not being ready")
+		}
+
+		if !strings.Contains(err.Error(), "in progress") {
+			t.Fatalf("Expected error to report progress, got: %v", err)
+		}
+
+		close(release)
+
+		for warmUp.Check(nil) != nil {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("Timed out waiting for warm-up to finish")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	t.Run("reports_ready_when_timed_out", func(t *testing.T) {
+		t.Parallel()
+
+		mockProvider, _ := recordingMockProvider(make(chan struct{}))
+
+		p, err := cache.NewCacheProvider(cache.ProviderOptions{ExternalProvider: mockProvider, CacheTTLSeconds: 30})
+		if err != nil {
+			t.Fatalf("Unexpected error creating the provider: %v", err)
+		}
+
+		warmUp := cache.NewWarmUp(p, cache.WarmUpOptions{Timeout: 10 * time.Millisecond})
+
+		runWarmUp(t, warmUp)
+
+		if err := warmUp.Check(nil); err != nil {
+			t.Fatalf("Expected warm-up to report being ready after timeout, got: %v", err)
+		}
+	})
+}
+
+func Test_Cache_warm_up_is_not_available_when_provider_is_not_cache_provider(t *testing.T) {
+	t.Parallel()
+
+	if warmUp := cache.NewWarmUp(&mock.Provider{}, cache.WarmUpOptions{}); warmUp != nil {
+		t.Fatalf("Expected no warm-up, got %v", warmUp)
+	}
+}
+
+// recordingMockProvider returns provider with two configured metrics, which records queried keys.
+// If release channel is not nil, queries block until it is closed or the request context is done.
+func recordingMockProvider(release chan struct{}) (*mock.Provider, func() []string) {
+	var lock sync.Mutex
+
+	queried := []string{}
+
+	mockProvider := &mock.Provider{
+		ListAllExternalMetricsFunc: func() []provider.ExternalMetricInfo {
+			return []provider.ExternalMetricInfo{{Metric: testMetricNameOne}, {Metric: testMetricNameTwo}}
+		},
+	}
+
+	defaultProvider := &mock.Provider{}
+
+	mockProvider.GetExternalMetricFunc = func(ctx context.Context, namespace string, selector labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+		if release != nil {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err() //nolint:wrapcheck // We don't care about wrapping in tests.
+			}
+		}
+
+		lock.Lock()
+		queried = append(queried, info.Metric+"/"+selector.String())
+		lock.Unlock()
+
+		return defaultProvider.GetExternalMetric(ctx, namespace, selector, info)
+	}
+
+	return mockProvider, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+
+		sort.Strings(queried)
+
+		return queried
+	}
+}
+
+func testWarmUp(t *testing.T, externalProvider provider.ExternalMetricsProvider, selectorsFile string) *cache.WarmUp {
+	t.Helper()
+
+	p, err := cache.NewCacheProvider(cache.ProviderOptions{ExternalProvider: externalProvider, CacheTTLSeconds: 30})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	return cache.NewWarmUp(p, cache.WarmUpOptions{SelectorsFile: selectorsFile})
+}
+
+// runWarmUp runs warm-up until it is finished.
+func runWarmUp(t *testing.T, warmUp *cache.WarmUp) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(testutil.ContextWithDeadline(t))
+
+	done := make(chan struct{})
+
+	go func() {
+		warmUp.Run(ctx)
+		close(done)
+	}()
+
+	for warmUp.Check(nil) != nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
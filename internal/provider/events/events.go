@@ -0,0 +1,188 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package events implements the external provider interface emitting Kubernetes Events on HPAs referencing
+// an external metric which the encapsulated provider failed to get, so owners of the HPAs can see the cause
+// of the failure without access to the adapter logs.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+)
+
+const (
+	// DefaultInterval is the minimum time between Events emitted on a single HPA for the same metric.
+	DefaultInterval = 5 * time.Minute
+
+	// ReasonQueryFailed is the reason of Events emitted when the query of an external metric fails.
+	ReasonQueryFailed = "ExternalMetricQueryFailed"
+
+	// ReasonFailed is the reason of Events emitted when getting an external metric fails for other reasons.
+	ReasonFailed = "ExternalMetricFailed"
+)
+
+// ProviderOptions holds the configuration of the events provider.
+type ProviderOptions struct {
+	ExternalProvider provider.ExternalMetricsProvider
+	// Lister lists HPAs, usually from a shared informer cache.
+	Lister autoscalingv2listers.HorizontalPodAutoscalerLister
+	// Recorder emits the Events.
+	Recorder record.EventRecorder
+	// Interval is the minimum time between Events emitted on a single HPA for the same metric.
+	// Defaults to DefaultInterval.
+	Interval time.Duration
+}
+
+type eventsProvider struct {
+	externalProvider provider.ExternalMetricsProvider
+	lister           autoscalingv2listers.HorizontalPodAutoscalerLister
+	recorder         record.EventRecorder
+	interval         time.Duration
+
+	mu sync.Mutex
+	// lastEmitted holds the time of the last Event emitted on each HPA for each metric.
+	lastEmitted map[emittedKey]time.Time
+}
+
+type emittedKey struct {
+	hpa    types.UID
+	metric string
+}
+
+// NewEventsProvider is the constructor for the events provider.
+func NewEventsProvider(options ProviderOptions) (provider.ExternalMetricsProvider, error) {
+	if options.ExternalProvider == nil {
+		return nil, fmt.Errorf("external provider must be configured")
+	}
+
+	if options.Lister == nil {
+		return nil, fmt.Errorf("HPA lister must be configured")
+	}
+
+	if options.Recorder == nil {
+		return nil, fmt.Errorf("event recorder must be configured")
+	}
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &eventsProvider{
+		externalProvider: options.ExternalProvider,
+		lister:           options.Lister,
+		recorder:         options.Recorder,
+		interval:         interval,
+		lastEmitted:      map[emittedKey]time.Time{},
+	}, nil
+}
+
+// GetExternalMetric returns the requested metric. When getting the metric fails, an Event is emitted on
+// each HPA in the namespace referencing the metric with the same selector.
+func (p *eventsProvider) GetExternalMetric(ctx context.Context, namespace string, match labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+	v, err := p.externalProvider.GetExternalMetric(ctx, namespace, match, info)
+	if err != nil {
+		p.emit(namespace, match, info.Metric, err)
+	}
+
+	return v, err //nolint:wrapcheck // Errors of the external provider carry the API status.
+}
+
+// ListAllExternalMetrics returns the list of external metrics supported by the encapsulated provider.
+func (p *eventsProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
+	return p.externalProvider.ListAllExternalMetrics()
+}
+
+func (p *eventsProvider) emit(namespace string, match labels.Selector, metric string, err error) {
+	hpas, listErr := p.lister.HorizontalPodAutoscalers(namespace).List(labels.Everything())
+	if listErr != nil {
+		klog.ErrorS(listErr, "Listing HPAs to emit Events on", logkeys.Namespace, namespace, logkeys.Metric, metric)
+
+		return
+	}
+
+	now := time.Now()
+
+	for _, hpa := range hpas {
+		if !referencesMetric(hpa, metric, match) || !p.allow(emittedKey{hpa: hpa.UID, metric: metric}, now) {
+			continue
+		}
+
+		failure, ok := newrelic.QueryFailureFrom(err)
+		if !ok {
+			p.recorder.Eventf(hpa, corev1.EventTypeWarning, ReasonFailed,
+				"Getting external metric %q failed: %v", metric, err)
+
+			continue
+		}
+
+		p.recorder.Eventf(hpa, corev1.EventTypeWarning, ReasonQueryFailed,
+			"Query of external metric %q failed with %s error: %v. Query: %s",
+			metric, failure.ErrorClass, failure.Err, failure.Query)
+	}
+}
+
+// allow returns true when no Event was emitted for the given key within the interval and records the
+// Event as emitted.
+func (p *eventsProvider) allow(key emittedKey, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if last, ok := p.lastEmitted[key]; ok && now.Sub(last) < p.interval {
+		return false
+	}
+
+	// Drop records of expired keys, so records of deleted HPAs do not accumulate.
+	for k, last := range p.lastEmitted {
+		if now.Sub(last) >= p.interval {
+			delete(p.lastEmitted, k)
+		}
+	}
+
+	p.lastEmitted[key] = now
+
+	return true
+}
+
+// referencesMetric returns true when the HPA has an external metric source of the given name and selector.
+func referencesMetric(hpa *autoscalingv2.HorizontalPodAutoscaler, metric string, match labels.Selector) bool {
+	requested := ""
+	if match != nil {
+		requested = match.String()
+	}
+
+	for _, spec := range hpa.Spec.Metrics {
+		if spec.Type != autoscalingv2.ExternalMetricSourceType || spec.External == nil {
+			continue
+		}
+
+		if spec.External.Metric.Name != metric {
+			continue
+		}
+
+		// The HPA controller requests the metric with its selector formatted the same way.
+		selector, err := metav1.LabelSelectorAsSelector(spec.External.Metric.Selector)
+		if err == nil && selector.String() == requested {
+			return true
+		}
+	}
+
+	return false
+}
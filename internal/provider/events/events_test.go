@@ -0,0 +1,270 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/events"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const (
+	testNamespace = "default"
+	testMetric    = "test_metric"
+)
+
+var testInfo = provider.ExternalMetricInfo{Metric: testMetric} //nolint:gochecknoglobals // Shared by all tests.
+
+//nolint:funlen // Just a large test suite.
+func Test_Getting_external_metric_which_fails(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	sl, err := labels.Parse("app=foo")
+	if err != nil {
+		t.Fatalf("Parsing selector: %v", err)
+	}
+
+	t.Run("emits_event_with_error_class_and_query_on_HPAs_referencing_metric_with_same_selector", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := record.NewFakeRecorder(10)
+
+		lister := testLister(t,
+			testHPA("matching", testNamespace, testMetric, map[string]string{"app": "foo"}),
+			testHPA("other-selector", testNamespace, testMetric, map[string]string{"app": "bar"}),
+			testHPA("other-metric", testNamespace, "other_metric", map[string]string{"app": "foo"}),
+			testHPA("other-namespace", "other", testMetric, map[string]string{"app": "foo"}),
+		)
+
+		p := testEventsProvider(t, failingQueryProvider(t), lister, recorder)
+
+		if _, err := p.GetExternalMetric(ctx, testNamespace, sl, testInfo); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		event := emittedEvents(recorder)
+		if len(event) != 1 {
+			t.Fatalf("Expected exactly 1 event, got %v", event)
+		}
+
+		for _, expected := range []string{
+			"Warning", events.ReasonQueryFailed, "timeout error", "select value from testSample where `app` = 'foo'",
+		} {
+			if !strings.Contains(event[0], expected) {
+				t.Errorf("Expected event to contain %q, got %q", expected, event[0])
+			}
+		}
+	})
+
+	t.Run("emits_event_with_error_when_failure_is_not_caused_by_query", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := record.NewFakeRecorder(10)
+
+		lister := testLister(t, testHPA("matching", testNamespace, testMetric, nil))
+
+		p := testEventsProvider(t, failingProvider(fmt.Errorf("storage unavailable")), lister, recorder)
+
+		if _, err := p.GetExternalMetric(ctx, testNamespace, nil, testInfo); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		event := emittedEvents(recorder)
+		if len(event) != 1 || !strings.Contains(event[0], events.ReasonFailed+" ") ||
+			!strings.Contains(event[0], "storage unavailable") {
+			t.Fatalf("Expected single event with error, got %v", event)
+		}
+	})
+
+	t.Run("emits_event_on_HPA_once_per_interval", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := record.NewFakeRecorder(10)
+
+		lister := testLister(t, testHPA("matching", testNamespace, testMetric, nil))
+
+		p := testEventsProvider(t, failingProvider(fmt.Errorf("storage unavailable")), lister, recorder)
+
+		for i := 0; i < 3; i++ {
+			if _, err := p.GetExternalMetric(ctx, testNamespace, nil, testInfo); err == nil {
+				t.Fatalf("Expected error")
+			}
+		}
+
+		if event := emittedEvents(recorder); len(event) != 1 {
+			t.Fatalf("Expected exactly 1 event, got %v", event)
+		}
+	})
+}
+
+func Test_Getting_external_metric_which_succeeds_emits_no_events(t *testing.T) {
+	t.Parallel()
+
+	recorder := record.NewFakeRecorder(10)
+
+	lister := testLister(t, testHPA("matching", testNamespace, testMetric, nil))
+
+	p := testEventsProvider(t, &mock.Provider{}, lister, recorder)
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	if _, err := p.GetExternalMetric(ctx, testNamespace, nil, testInfo); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if event := emittedEvents(recorder); len(event) != 0 {
+		t.Fatalf("Expected no events, got %v", event)
+	}
+}
+
+func Test_Creating_events_provider_fails_when(t *testing.T) {
+	t.Parallel()
+
+	lister := testLister(t)
+	recorder := record.NewFakeRecorder(1)
+
+	cases := map[string]events.ProviderOptions{
+		"external_provider_is_not_configured": {Lister: lister, Recorder: recorder},
+		"lister_is_not_configured":            {ExternalProvider: &mock.Provider{}, Recorder: recorder},
+		"recorder_is_not_configured":          {ExternalProvider: &mock.Provider{}, Lister: lister},
+	}
+
+	for testCaseName, options := range cases {
+		options := options
+
+		t.Run(testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := events.NewEventsProvider(options); err == nil {
+				t.Fatalf("Expected error creating the provider")
+			}
+		})
+	}
+}
+
+func testEventsProvider(
+	t *testing.T,
+	p provider.ExternalMetricsProvider,
+	lister autoscalingv2listers.HorizontalPodAutoscalerLister,
+	recorder record.EventRecorder,
+) provider.ExternalMetricsProvider {
+	t.Helper()
+
+	eventsProvider, err := events.NewEventsProvider(events.ProviderOptions{
+		ExternalProvider: p,
+		Lister:           lister,
+		Recorder:         recorder,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	return eventsProvider
+}
+
+func testLister(t *testing.T, hpas ...*autoscalingv2.HorizontalPodAutoscaler) autoscalingv2listers.HorizontalPodAutoscalerLister { //nolint:lll // Just a long signature.
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	for _, hpa := range hpas {
+		if err := indexer.Add(hpa); err != nil {
+			t.Fatalf("Adding HPA: %v", err)
+		}
+	}
+
+	return autoscalingv2listers.NewHorizontalPodAutoscalerLister(indexer)
+}
+
+func testHPA(name, namespace, metric string, matchLabels map[string]string) *autoscalingv2.HorizontalPodAutoscaler {
+	metricIdentifier := autoscalingv2.MetricIdentifier{Name: metric}
+	if matchLabels != nil {
+		metricIdentifier.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(namespace + "/" + name),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type:     autoscalingv2.ExternalMetricSourceType,
+					External: &autoscalingv2.ExternalMetricSource{Metric: metricIdentifier},
+				},
+			},
+		},
+	}
+}
+
+func failingProvider(err error) provider.ExternalMetricsProvider {
+	return &mock.Provider{
+		GetExternalMetricFunc: func(_ context.Context, _ string, _ labels.Selector, _ provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+			return nil, err
+		},
+	}
+}
+
+// failingQueryProvider returns direct provider which query of the test metric times out.
+func failingQueryProvider(t *testing.T) provider.ExternalMetricsProvider {
+	t.Helper()
+
+	p, err := newrelic.NewDirectProvider(newrelic.ProviderOptions{
+		ExternalMetrics: map[string]newrelic.Metric{
+			testMetric: {Query: "select value from testSample", RemoveClusterFilter: true},
+		},
+		NRDBClient:   &timingOutClient{},
+		AccountID:    1,
+		RegisterFunc: metrics.NewKubeRegistry().Register,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the provider: %v", err)
+	}
+
+	return p
+}
+
+type timingOutClient struct{}
+
+func (timingOutClient) QueryWithContext(_ context.Context, _ int, _ nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	return nil, context.DeadlineExceeded
+}
+
+// emittedEvents returns events recorded by the recorder so far.
+func emittedEvents(recorder *record.FakeRecorder) []string {
+	emitted := []string{}
+
+	for {
+		select {
+		case event := <-recorder.Events:
+			emitted = append(emitted, event)
+		default:
+			return emitted
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultCircuitBreakerOpenDuration is the time an open circuit breaker fails queries before probing
+	// recovery when none is configured.
+	DefaultCircuitBreakerOpenDuration = 30 * time.Second
+
+	// Scopes of circuit breakers.
+	breakerScopeConnection = "connection"
+	breakerScopeMetric     = "metric"
+
+	errorClassCircuitOpen = "circuit_open"
+)
+
+// Circuit breaker states, exported as values of the state gauge.
+const (
+	breakerClosed   = 0
+	breakerHalfOpen = 1
+	breakerOpen     = 2
+)
+
+//nolint:gochecknoglobals // Read-only lookup table.
+var breakerStateNames = map[int]string{
+	breakerClosed:   "closed",
+	breakerHalfOpen: "half-open",
+	breakerOpen:     "open",
+}
+
+// CircuitBreakerOptions holds the configuration of circuit breakers guarding queries.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive transient failures after which the breaker opens.
+	// Zero disables circuit breakers.
+	FailureThreshold int
+	// OpenDuration is the time the breaker stays open before letting a single query probe recovery.
+	OpenDuration time.Duration
+}
+
+// circuitOpenError is returned for queries rejected by an open circuit breaker.
+type circuitOpenError struct {
+	scope    string
+	metric   string
+	retryIn  time.Duration
+	failures int
+}
+
+func (e *circuitOpenError) Error() string {
+	name := e.scope
+	if e.metric != "" {
+		name = fmt.Sprintf("%s %q", e.scope, e.metric)
+	}
+
+	return fmt.Sprintf("circuit breaker for %s is open after %d consecutive failures, next attempt in %v",
+		name, e.failures, e.retryIn.Round(time.Second))
+}
+
+// ErrorClass reports the class of the error, so rejected queries can be accounted separately.
+func (e *circuitOpenError) ErrorClass() string {
+	return errorClassCircuitOpen
+}
+
+type circuitBreaker struct {
+	scope            string
+	metric           string
+	source           string
+	failureThreshold int
+	openDuration     time.Duration
+	metrics          providerMetrics
+
+	lock     sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns an error if the query should not be executed. When the breaker is half-open, only a single
+// query is allowed to probe recovery at a time.
+func (b *circuitBreaker) allow() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if retryIn := b.openDuration - time.Since(b.openedAt); retryIn > 0 {
+			return &circuitOpenError{scope: b.scope, metric: b.metric, retryIn: retryIn, failures: b.failures}
+		}
+
+		b.setState(breakerHalfOpen)
+	case breakerHalfOpen:
+		if b.probing {
+			return &circuitOpenError{scope: b.scope, metric: b.metric, failures: b.failures}
+		}
+	}
+
+	b.probing = b.state == breakerHalfOpen
+
+	return nil
+}
+
+// isOpen returns true when the breaker fails queries. Breaker open for longer than the open duration lets
+// the next query probe recovery, so it is reported as half-open even when no query has arrived yet.
+func (b *circuitBreaker) isOpen() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state == breakerOpen && time.Since(b.openedAt) < b.openDuration
+}
+
+// release gives up the probe allowed by allow without recording its result.
+func (b *circuitBreaker) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+}
+
+// record records the result of the allowed query.
+func (b *circuitBreaker) record(failed bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+
+	if !failed {
+		b.failures = 0
+		b.setState(breakerClosed)
+
+		return
+	}
+
+	b.failures++
+
+	if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+		b.openedAt = time.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+func (b *circuitBreaker) setState(state int) {
+	if b.state != state {
+		klog.InfoS("Circuit breaker changed state", logkeys.Scope, b.scope, logkeys.Metric, b.metric,
+			logkeys.Source, b.source, logkeys.PreviousState, breakerStateNames[b.state], logkeys.State, breakerStateNames[state],
+			logkeys.Failures, b.failures)
+	}
+
+	b.state = state
+	b.metrics.circuitBreakerState.WithLabelValues(b.scope, b.metric, b.source).Set(float64(state))
+}
+
+// breakingClient guards queries with a circuit breaker for the whole NerdGraph connection, which opens when
+// New Relic is unavailable, and with a circuit breaker per metric, which opens when a single query keeps failing.
+type breakingClient struct {
+	client     NRDBClient
+	options    CircuitBreakerOptions
+	metrics    providerMetrics
+	connection *circuitBreaker
+	breakers   *sync.Map
+}
+
+func newBreakingClient(client NRDBClient, options CircuitBreakerOptions, metrics providerMetrics) *breakingClient {
+	if options.OpenDuration <= 0 {
+		options.OpenDuration = DefaultCircuitBreakerOpenDuration
+	}
+
+	c := &breakingClient{
+		client:   client,
+		options:  options,
+		metrics:  metrics,
+		breakers: &sync.Map{},
+	}
+
+	c.connection = c.newBreaker(breakerScopeConnection, "", "")
+	c.connection.setState(breakerClosed)
+
+	return c
+}
+
+func (c *breakingClient) newBreaker(scope, metric, source string) *circuitBreaker {
+	b := &circuitBreaker{
+		scope:            scope,
+		metric:           metric,
+		source:           source,
+		failureThreshold: c.options.FailureThreshold,
+		openDuration:     c.options.OpenDuration,
+		metrics:          c.metrics,
+	}
+
+	return b
+}
+
+// metricBreakerKey identifies the breaker of queries of a metric fetching the value from a single source, so
+// fallback queries of the metric have breakers of their own.
+type metricBreakerKey struct {
+	metric string
+	source string
+}
+
+func (c *breakingClient) metricBreaker(metric, source string) *circuitBreaker {
+	key := metricBreakerKey{metric: metric, source: source}
+
+	if b, ok := c.breakers.Load(key); ok {
+		return b.(*circuitBreaker) //nolint:forcetypeassert // Breakers should always be of this type.
+	}
+
+	value, loaded := c.breakers.LoadOrStore(key, c.newBreaker(breakerScopeMetric, metric, source))
+
+	b := value.(*circuitBreaker) //nolint:forcetypeassert // Breakers should always be of this type.
+	if !loaded {
+		b.lock.Lock()
+		b.setState(breakerClosed)
+		b.lock.Unlock()
+	}
+
+	return b
+}
+
+// metricOpen returns true when the breaker of the query of the given metric fails queries.
+func (c *breakingClient) metricOpen(metric string) bool {
+	b, ok := c.breakers.Load(metricBreakerKey{metric: metric, source: sourceQuery})
+
+	return ok && b.(*circuitBreaker).isOpen() //nolint:forcetypeassert // Breakers should always be of this type.
+}
+
+func (c *breakingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	metricBreaker := c.metricBreaker(metricFromContext(ctx), querySourceFromContext(ctx))
+
+	if err := metricBreaker.allow(); err != nil {
+		return nil, err
+	}
+
+	if err := c.connection.allow(); err != nil {
+		metricBreaker.release()
+
+		return nil, err
+	}
+
+	result, err := c.client.QueryWithContext(ctx, accountID, query)
+
+	// Cancelled queries, e.g. hedged queries which lost the race, and queries rejected by the adapter itself
+	// did not get a response from the backend, so they say nothing about its health.
+	if err != nil && !fromBackend(ctx, err) {
+		metricBreaker.release()
+		c.connection.release()
+
+		return nil, err //nolint:wrapcheck // Decorator should not alter errors.
+	}
+
+	// Only failures indicating unavailability count, as e.g. invalid query says nothing about the backend health.
+	failed := err != nil && isTransient(classifyError(err))
+
+	metricBreaker.record(failed)
+	c.connection.record(failed)
+
+	return result, err //nolint:wrapcheck // Decorator should not alter errors.
+}
+
+// fromBackend returns true when the given query error was caused by the backend rather than by cancellation
+// of the query or its rejection by the adapter.
+func fromBackend(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.Canceled) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	switch classifyError(err) {
+	case errorClassRejected, errorClassConcurrencyLimited, errorClassCircuitOpen:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	nrErrors "github.com/newrelic/newrelic-client-go/v2/pkg/errors"
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const failingMetricName = "failing_metric"
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_with_circuit_breaker_enabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("fails_fast_after_consecutive_transient_failures", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{alwaysFail: nrErrors.NewUnexpectedStatusCode(503, "service unavailable")}
+
+		registry := metrics.NewKubeRegistry()
+
+		p := testProvider(t, breakerProviderOptions(client, time.Hour, registry.Register))
+
+		var err error
+
+		for i := 0; i < 3; i++ {
+			_, err = p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		}
+
+		if err == nil || !strings.Contains(err.Error(), "circuit breaker") {
+			t.Fatalf("Expected circuit breaker error, got: %v", err)
+		}
+
+		if calls := client.calls(); calls != 2 {
+			t.Fatalf("Expected 2 queries, got %d", calls)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_circuit_breaker_state [ALPHA] State of the circuit breaker guarding queries to the NewRelic backend: 0 closed, 1 half-open, 2 open.
+		# TYPE newrelic_adapter_external_provider_circuit_breaker_state gauge
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="",scope="connection",source=""} 2
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="test_metric",scope="metric",source="query"} 2
+		`
+
+		metricName := "newrelic_adapter_external_provider_circuit_breaker_state"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("does_not_open_on_non_transient_failures", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{alwaysFail: nrErrors.NewUnauthorizedError()}
+
+		p := testProvider(t, breakerProviderOptions(client, time.Hour, nil))
+
+		for i := 0; i < 3; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+				t.Fatalf("Expected error")
+			}
+		}
+
+		if calls := client.calls(); calls != 3 {
+			t.Fatalf("Expected 3 queries, got %d", calls)
+		}
+	})
+
+	t.Run("closes_when_probe_succeeds_after_open_duration", func(t *testing.T) {
+		t.Parallel()
+
+		unavailable := nrErrors.NewUnexpectedStatusCode(503, "service unavailable")
+		client := &sequenceClient{errs: []error{unavailable, unavailable}}
+
+		p := testProvider(t, breakerProviderOptions(client, 10*time.Millisecond, nil))
+
+		for i := 0; i < 2; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+				t.Fatalf("Expected error")
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 0; i < 2; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("does_not_close_when_probe_is_cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		unavailable := nrErrors.NewUnexpectedStatusCode(503, "service unavailable")
+		client := &sequenceClient{errs: []error{unavailable, unavailable, context.Canceled}}
+
+		registry := metrics.NewKubeRegistry()
+
+		p := testProvider(t, breakerProviderOptions(client, 10*time.Millisecond, registry.Register))
+
+		for i := 0; i < 3; i++ {
+			if i == 2 {
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+				t.Fatalf("Expected error")
+			}
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_circuit_breaker_state [ALPHA] State of the circuit breaker guarding queries to the NewRelic backend: 0 closed, 1 half-open, 2 open.
+		# TYPE newrelic_adapter_external_provider_circuit_breaker_state gauge
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="",scope="connection",source=""} 1
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="test_metric",scope="metric",source="query"} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_circuit_breaker_state"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+
+		// Released probe lets the next query probe recovery.
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("opens_only_for_failing_metric", func(t *testing.T) {
+		t.Parallel()
+
+		options := breakerProviderOptions(&metricFailingClient{}, time.Hour, nil)
+		options.ExternalMetrics[failingMetricName] = newrelic.Metric{Query: "select failing from testSample"}
+
+		p := testProvider(t, options)
+
+		for i := 0; i < 3; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: failingMetricName}); err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: failingMetricName})
+		if err == nil || !strings.Contains(err.Error(), "circuit breaker") {
+			t.Fatalf("Expected circuit breaker error, got: %v", err)
+		}
+	})
+}
+
+// metricFailingClient fails queries of the failing metric, or all queries if configured, with a server error.
+type metricFailingClient struct {
+	failAll bool
+}
+
+func (c *metricFailingClient) QueryWithContext(_ context.Context, _ int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	if c.failAll || strings.Contains(string(query), "failing") {
+		return nil, nrErrors.NewUnexpectedStatusCode(504, "gateway timeout")
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{{"value": float64(1)}},
+	}, nil
+}
+
+func breakerProviderOptions(
+	client newrelic.NRDBClient, openDuration time.Duration, registerFunc func(metrics.Registerable) error,
+) newrelic.ProviderOptions {
+	options, _ := testProviderOptions()
+	options.NRDBClient = client
+	options.RegisterFunc = registerFunc
+	options.CircuitBreaker = newrelic.CircuitBreakerOptions{
+		FailureThreshold: 2,
+		OpenDuration:     openDuration,
+	}
+
+	return options
+}
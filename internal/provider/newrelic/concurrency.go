@@ -0,0 +1,69 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+)
+
+const errorClassConcurrencyLimited = "concurrency_limited"
+
+// queueTimeoutError is returned for queries which did not get a free slot before their context was done.
+type queueTimeoutError struct {
+	err error
+}
+
+func (e *queueTimeoutError) Error() string {
+	return fmt.Sprintf("waiting for free query slot: %v", e.err)
+}
+
+func (e *queueTimeoutError) Unwrap() error {
+	return e.err
+}
+
+// ErrorClass reports the class of the error, so queued queries are not mistaken for backend failures.
+func (e *queueTimeoutError) ErrorClass() string {
+	return errorClassConcurrencyLimited
+}
+
+// concurrencyLimitingClient bounds the number of queries executed at the same time. Queries over the limit
+// are queued until a slot is free or their context is done.
+type concurrencyLimitingClient struct {
+	client  NRDBClient
+	slots   chan struct{}
+	metrics providerMetrics
+}
+
+func newConcurrencyLimitingClient(client NRDBClient, maxConcurrent int, metrics providerMetrics) *concurrencyLimitingClient {
+	return &concurrencyLimitingClient{
+		client:  client,
+		slots:   make(chan struct{}, maxConcurrent),
+		metrics: metrics,
+	}
+}
+
+func (c *concurrencyLimitingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	c.metrics.queuedQueries.Inc()
+
+	select {
+	case c.slots <- struct{}{}:
+		c.metrics.queuedQueries.Dec()
+	case <-ctx.Done():
+		c.metrics.queuedQueries.Dec()
+
+		return nil, &queueTimeoutError{err: ctx.Err()}
+	}
+
+	c.metrics.inFlightQueries.Inc()
+
+	defer func() {
+		c.metrics.inFlightQueries.Dec()
+		<-c.slots
+	}()
+
+	return c.client.QueryWithContext(ctx, accountID, query) //nolint:wrapcheck // Decorator should not alter errors.
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen // Just a large test suite.
+func Test_Getting_external_metric_with_concurrency_limit(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("executes_at_most_configured_number_of_queries_at_the_same_time", func(t *testing.T) {
+		t.Parallel()
+
+		client := &blockingClient{release: make(chan struct{})}
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.NRDBClient = client
+		options.MaxConcurrentQueries = 2
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}()
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_in_flight_queries [ALPHA] Number of queries to the NewRelic backend currently being executed.
+		# TYPE newrelic_adapter_external_provider_in_flight_queries gauge
+		newrelic_adapter_external_provider_in_flight_queries 2
+		# HELP newrelic_adapter_external_provider_queued_queries [ALPHA] Number of queries to the NewRelic backend waiting for a free slot.
+		# TYPE newrelic_adapter_external_provider_queued_queries gauge
+		newrelic_adapter_external_provider_queued_queries 3
+		`
+
+		metricNames := []string{
+			"newrelic_adapter_external_provider_in_flight_queries",
+			"newrelic_adapter_external_provider_queued_queries",
+		}
+
+		// Wait for the queries to either be executed or queued.
+		for {
+			err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricNames...)
+			if err == nil {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				t.Fatalf("Unexpected metric value: %v", err)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+
+		close(client.release)
+		wg.Wait()
+
+		if maxInFlight := atomic.LoadInt32(&client.maxInFlight); maxInFlight != 2 {
+			t.Fatalf("Expected at most 2 queries at the same time, got %d", maxInFlight)
+		}
+	})
+
+	t.Run("fails_queued_query_when_request_context_is_done", func(t *testing.T) {
+		t.Parallel()
+
+		client := &blockingClient{release: make(chan struct{})}
+		defer close(client.release)
+
+		options, _ := testProviderOptions()
+		options.NRDBClient = client
+		options.MaxConcurrentQueries = 1
+
+		p := testProvider(t, options)
+
+		go func() {
+			//nolint:errcheck // Only occupies the slot.
+			_, _ = p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		}()
+
+		for atomic.LoadInt32(&client.inFlight) < 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+	})
+}
+
+// blockingClient blocks queries until release channel is closed, tracking the number of concurrent queries.
+type blockingClient struct {
+	release     chan struct{}
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (c *blockingClient) QueryWithContext(ctx context.Context, _ int, _ nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	inFlight := atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+
+	for {
+		maxInFlight := atomic.LoadInt32(&c.maxInFlight)
+		if inFlight <= maxInFlight || atomic.CompareAndSwapInt32(&c.maxInFlight, maxInFlight, inFlight) {
+			break
+		}
+	}
+
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return nil, ctx.Err() //nolint:wrapcheck // We don't care about wrapping in tests.
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{{"value": float64(1)}},
+	}, nil
+}
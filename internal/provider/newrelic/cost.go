@@ -0,0 +1,289 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultCostEstimateWindow is the period of recent queries extrapolated to the daily estimates of query
+	// volume.
+	DefaultCostEstimateWindow = time.Hour
+
+	// Queries observed for a shorter period are extrapolated as if observed for this long, so the first queries
+	// after start do not inflate the estimates.
+	minCostEstimatePeriod = time.Minute
+
+	// Queries are counted in buckets of this period, so estimates do not keep every query of the window.
+	costBucketPeriod = time.Minute
+
+	day = 24 * time.Hour
+
+	// Keys of performance statistics in the raw response of NRDB.
+	performanceStatsKey = "performanceStats"
+	inspectedCountKey   = "inspectedCount"
+	wallClockTimeKey    = "wallClockTime"
+
+	// NRDB reports wall-clock time in milliseconds.
+	wallClockTimeFactor = 1000
+)
+
+// CostOptions holds the configuration of accounting the cost of queries.
+type CostOptions struct {
+	// EstimateWindow is the period of recent queries extrapolated to the daily estimates, rounded up to whole
+	// minutes. Defaults to DefaultCostEstimateWindow.
+	EstimateWindow time.Duration
+}
+
+// ExtendedResponseClient executes NRQL queries returning the extended response of NerdGraph.
+type ExtendedResponseClient interface {
+	QueryWithExtendedResponseWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) //nolint:lll // External interface requirement.
+}
+
+type extendedResponseClient struct {
+	client ExtendedResponseClient
+}
+
+// WithPerformanceStats returns client requesting the extended response for every query, which includes
+// the raw response of NRDB with the number of inspected events and the wall-clock time of the query.
+// The extended response is larger, so it is requested only when these statistics are accounted.
+func WithPerformanceStats(client ExtendedResponseClient) NRDBClient {
+	return &extendedResponseClient{client: client}
+}
+
+func (c *extendedResponseClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	//nolint:wrapcheck // Adapter should not alter errors.
+	return c.client.QueryWithExtendedResponseWithContext(ctx, accountID, query)
+}
+
+// costBucket counts queries of a single metric sent to NRDB within a single bucket period.
+type costBucket struct {
+	// index is the number of bucket periods since the Unix epoch the counts belong to.
+	index           int64
+	queries         float64
+	inspectedEvents float64
+}
+
+// metricCost holds counts of queries of a single metric sent within the estimate window, bucketed by time,
+// so memory does not grow with the query rate.
+type metricCost struct {
+	mu      sync.Mutex
+	buckets []costBucket
+	// lastMessages holds messages returned by NRDB for the latest query of the metric.
+	lastMessages []string
+}
+
+// costRecorder records queries sent to NRDB to estimate the daily query volume of each metric. Queries
+// update only counts of the queried metric, estimates are computed when scraped or requested for status.
+type costRecorder struct {
+	buckets int
+	started time.Time
+	metrics providerMetrics
+
+	cost sync.Map
+}
+
+// newCostRecorder returns recorder of query costs, which computes estimates exported by the given metrics
+// when they are collected.
+func newCostRecorder(options CostOptions, providerMetrics providerMetrics, now time.Time) *costRecorder {
+	window := options.EstimateWindow
+	if window <= 0 {
+		window = DefaultCostEstimateWindow
+	}
+
+	r := &costRecorder{
+		// Window is rounded up to whole bucket periods.
+		buckets: int((window + costBucketPeriod - 1) / costBucketPeriod),
+		started: now,
+		metrics: providerMetrics,
+	}
+
+	providerMetrics.estimatedDailyQueries.collect = r.updateEstimates
+	providerMetrics.estimatedDailyInspectedEvents.collect = r.updateEstimates
+
+	return r
+}
+
+func (r *costRecorder) metricCost(name string) *metricCost {
+	cost, ok := r.cost.Load(name)
+	if !ok {
+		cost, _ = r.cost.LoadOrStore(name, &metricCost{buckets: make([]costBucket, r.buckets)})
+	}
+
+	return cost.(*metricCost) //nolint:forcetypeassert // Costs should always be of this type.
+}
+
+// recordQuery records a query of the given metric with the given number of inspected events.
+func (r *costRecorder) recordQuery(name string, inspectedCount float64, now time.Time) {
+	cost := r.metricCost(name)
+
+	cost.mu.Lock()
+	defer cost.mu.Unlock()
+
+	index := now.UnixNano() / int64(costBucketPeriod)
+
+	bucket := &cost.buckets[index%int64(len(cost.buckets))]
+	if bucket.index != index {
+		*bucket = costBucket{index: index}
+	}
+
+	bucket.queries++
+	bucket.inspectedEvents += inspectedCount
+}
+
+// recordMessages records messages returned by NRDB for the latest query of the given metric and reports
+// whether they differ from messages of the previous query.
+func (r *costRecorder) recordMessages(name string, messages []string) bool {
+	cost := r.metricCost(name)
+
+	cost.mu.Lock()
+	defer cost.mu.Unlock()
+
+	changed := !slices.Equal(cost.lastMessages, messages)
+	cost.lastMessages = messages
+
+	return changed
+}
+
+// dailyEstimate returns estimated number of queries of the given metric per day and number of events they
+// inspect, extrapolated from queries sent within the estimate window.
+func (r *costRecorder) dailyEstimate(name string, now time.Time) (float64, float64) {
+	cost, ok := r.cost.Load(name)
+	if !ok {
+		return 0, 0
+	}
+
+	return r.estimate(cost.(*metricCost), now) //nolint:forcetypeassert // Costs should always be of this type.
+}
+
+// updateEstimates sets estimates of all metrics, so estimates of metrics no longer queried decay as well.
+func (r *costRecorder) updateEstimates() {
+	now := time.Now()
+
+	r.cost.Range(func(key, value any) bool {
+		name, cost := key.(string), value.(*metricCost) //nolint:forcetypeassert // Costs should always be of these types.
+		queries, inspectedEvents := r.estimate(cost, now)
+
+		r.metrics.estimatedDailyQueries.WithLabelValues(name).Set(queries)
+		r.metrics.estimatedDailyInspectedEvents.WithLabelValues(name).Set(inspectedEvents)
+
+		return true
+	})
+}
+
+// estimate extrapolates counts of queries within the estimate window to a day.
+func (r *costRecorder) estimate(cost *metricCost, now time.Time) (float64, float64) {
+	queries, inspectedEvents := cost.counts(now)
+
+	// Buckets cover whole periods before the current one and the elapsed part of the current one.
+	covered := time.Duration(r.buckets-1)*costBucketPeriod + now.Sub(now.Truncate(costBucketPeriod))
+
+	period := min(now.Sub(r.started), covered)
+	period = max(period, minCostEstimatePeriod)
+
+	factor := float64(day) / float64(period)
+
+	return queries * factor, inspectedEvents * factor
+}
+
+// counts returns the number of queries and inspected events within buckets of the estimate window.
+func (c *metricCost) counts(now time.Time) (float64, float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := now.UnixNano() / int64(costBucketPeriod)
+	queries, inspectedEvents := 0.0, 0.0
+
+	for _, bucket := range c.buckets {
+		if bucket.index > index-int64(len(c.buckets)) && bucket.index <= index {
+			queries += bucket.queries
+			inspectedEvents += bucket.inspectedEvents
+		}
+	}
+
+	return queries, inspectedEvents
+}
+
+// accountingClient accounts every query sent to NRDB, together with the performance statistics and
+// messages returned by NRDB, so the query volume generated by HPAs can be monitored.
+type accountingClient struct {
+	client  NRDBClient
+	costs   *costRecorder
+	metrics providerMetrics
+}
+
+func newAccountingClient(client NRDBClient, costs *costRecorder, providerMetrics providerMetrics) *accountingClient {
+	return &accountingClient{
+		client:  client,
+		costs:   costs,
+		metrics: providerMetrics,
+	}
+}
+
+func (c *accountingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	result, err := c.client.QueryWithContext(ctx, accountID, query)
+
+	name := metricFromContext(ctx)
+	if name == "" {
+		return result, err //nolint:wrapcheck // Decorator should not alter errors.
+	}
+
+	inspectedCount := 0.0
+
+	if err == nil && result != nil {
+		if count, wallClockTime, ok := performanceStats(result.RawResponse); ok {
+			inspectedCount = count
+
+			c.metrics.queryInspectedEventsTotal.WithLabelValues(name).Add(inspectedCount)
+			c.metrics.queryWallClockTime.WithLabelValues(name).Observe(wallClockTime.Seconds())
+		}
+
+		c.recordMessages(name, query, result.Metadata.Messages)
+	}
+
+	c.costs.recordQuery(name, inspectedCount, time.Now())
+
+	return result, err //nolint:wrapcheck // Decorator should not alter errors.
+}
+
+// recordMessages counts messages returned by NRDB and logs them when they change, so a metric which keeps
+// returning the same warning does not flood the logs.
+func (c *accountingClient) recordMessages(name string, query nrdb.NRQL, messages []string) {
+	if len(messages) > 0 {
+		c.metrics.queryMessagesTotal.WithLabelValues(name).Add(float64(len(messages)))
+	}
+
+	if !c.costs.recordMessages(name, messages) {
+		return
+	}
+
+	for _, message := range messages {
+		klog.InfoS("NRDB returned message with query result",
+			logkeys.Metric, name, logkeys.Query, query, logkeys.Message, message)
+	}
+}
+
+// performanceStats returns the number of events inspected by a query and its wall-clock time from the raw
+// response of NRDB. False is returned when the raw response holds no statistics, e.g. when it was not
+// requested.
+func performanceStats(raw nrdb.NRDBRawResults) (float64, time.Duration, bool) {
+	stats, ok := raw[performanceStatsKey].(map[string]interface{})
+	if !ok {
+		return 0, 0, false
+	}
+
+	inspectedCount, _ := stats[inspectedCountKey].(float64)
+	wallClockTime, _ := stats[wallClockTimeKey].(float64)
+
+	return inspectedCount, time.Duration(wallClockTime * float64(time.Second) / wallClockTimeFactor), true
+}
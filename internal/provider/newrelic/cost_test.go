@@ -0,0 +1,178 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_accounts(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("inspected_events_wall_clock_time_and_messages_from_NRDB_response", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, client := testProviderOptions()
+		options.NRDBClient = newrelic.WithPerformanceStats(&extendedResponseClient{client: client})
+		options.RegisterFunc = registry.Register
+
+		client.response.RawResponse = nrdb.NRDBRawResults{
+			"performanceStats": map[string]interface{}{
+				"inspectedCount": float64(1000),
+				"wallClockTime":  float64(250),
+			},
+		}
+		client.response.Metadata.Messages = []string{"Your query's time range was adjusted"}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		//nolint:lll // Expected metrics are not wrapped.
+		expected := `
+		# HELP newrelic_adapter_external_provider_query_inspected_events_total [ALPHA] Total number of events inspected by NRDB for queries of the external metric, as reported by NRDB performance statistics.
+		# TYPE newrelic_adapter_external_provider_query_inspected_events_total counter
+		newrelic_adapter_external_provider_query_inspected_events_total{metric="test_metric"} 1000
+		# HELP newrelic_adapter_external_provider_query_messages_total [ALPHA] Total number of messages, usually warnings, returned by NRDB with results of queries of the external metric.
+		# TYPE newrelic_adapter_external_provider_query_messages_total counter
+		newrelic_adapter_external_provider_query_messages_total{metric="test_metric"} 1
+		`
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected),
+			"newrelic_adapter_external_provider_query_inspected_events_total",
+			"newrelic_adapter_external_provider_query_messages_total",
+		); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+
+		name := "newrelic_adapter_external_provider_query_wall_clock_seconds"
+
+		if series := gatheredSeries(t, registry, name); series != 1 {
+			t.Errorf("Expected 1 series of %q, got %d", name, series)
+		}
+	})
+
+	t.Run("daily_estimates_of_queries_and_inspected_events", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, client := testProviderOptions()
+		options.NRDBClient = newrelic.WithPerformanceStats(&extendedResponseClient{client: client})
+		options.RegisterFunc = registry.Register
+
+		client.response.RawResponse = nrdb.NRDBRawResults{
+			"performanceStats": map[string]interface{}{"inspectedCount": float64(1000)},
+		}
+
+		p := testProvider(t, options)
+
+		for i := 0; i < 2; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		statuses := newrelic.MetricStatuses(p)
+		if len(statuses) != 1 {
+			t.Fatalf("Expected status of 1 metric, got %d", len(statuses))
+		}
+
+		// Queries observed for less than a minute are extrapolated as if observed for a minute.
+		minutesPerDay := float64(24 * 60)
+
+		if expected := 2 * minutesPerDay; statuses[0].EstimatedDailyQueries != expected {
+			t.Errorf("Expected %v daily queries, got %v", expected, statuses[0].EstimatedDailyQueries)
+		}
+
+		if expected := 2000 * minutesPerDay; statuses[0].EstimatedDailyInspectedEvents != expected {
+			t.Errorf("Expected %v daily inspected events, got %v", expected, statuses[0].EstimatedDailyInspectedEvents)
+		}
+
+		//nolint:lll // Expected metrics are not wrapped.
+		expected := `
+		# HELP newrelic_adapter_external_provider_estimated_daily_inspected_events [ALPHA] Estimated number of events inspected by NRDB per day for queries of the external metric, extrapolated from recent queries.
+		# TYPE newrelic_adapter_external_provider_estimated_daily_inspected_events gauge
+		newrelic_adapter_external_provider_estimated_daily_inspected_events{metric="test_metric"} 2.88e+06
+		# HELP newrelic_adapter_external_provider_estimated_daily_queries [ALPHA] Estimated number of queries of the external metric sent to the NewRelic backend per day, extrapolated from recent queries.
+		# TYPE newrelic_adapter_external_provider_estimated_daily_queries gauge
+		newrelic_adapter_external_provider_estimated_daily_queries{metric="test_metric"} 2880
+		`
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected),
+			"newrelic_adapter_external_provider_estimated_daily_inspected_events",
+			"newrelic_adapter_external_provider_estimated_daily_queries",
+		); err != nil {
+			t.Fatalf("Unexpected estimates computed when scraped: %v", err)
+		}
+	})
+
+	t.Run("failed_queries_in_daily_estimate_of_queries", func(t *testing.T) {
+		t.Parallel()
+
+		options, client := testProviderOptions()
+		client.response = nil
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if statuses := newrelic.MetricStatuses(p); statuses[0].EstimatedDailyQueries == 0 {
+			t.Errorf("Expected failed query to be estimated, got %+v", statuses[0])
+		}
+	})
+
+	t.Run("no_performance_statistics_without_raw_response", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for name, expected := range map[string]int{
+			"newrelic_adapter_external_provider_query_inspected_events_total": 0,
+			"newrelic_adapter_external_provider_query_wall_clock_seconds":     0,
+			"newrelic_adapter_external_provider_estimated_daily_queries":      1,
+		} {
+			if series := gatheredSeries(t, registry, name); series != expected {
+				t.Errorf("Expected %d series of %q, got %d", expected, name, series)
+			}
+		}
+	})
+}
+
+// extendedResponseClient returns responses of the given client as extended responses.
+type extendedResponseClient struct {
+	client newrelic.NRDBClient
+}
+
+func (c *extendedResponseClient) QueryWithExtendedResponseWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	return c.client.QueryWithContext(ctx, accountID, query) //nolint:wrapcheck // Test client should not alter errors.
+}
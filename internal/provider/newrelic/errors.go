@@ -0,0 +1,298 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	nrErrors "github.com/newrelic/newrelic-client-go/v2/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Classes of errors returned by the NRDB client.
+const (
+	errorClassTimeout        = "timeout"
+	errorClassNetwork        = "network"
+	errorClassServer         = "server"
+	errorClassRateLimited    = "rate_limited"
+	errorClassAuthentication = "authentication"
+	errorClassAuthorization  = "authorization"
+	errorClassNRQLSyntax     = "nrql_syntax"
+	errorClassQuery          = "query"
+	errorClassRejected       = "rejected"
+	errorClassUnknown        = "unknown"
+)
+
+// NerdGraph error classes and codes reported in error extensions.
+const (
+	graphQLErrorClassTimeout             = "TIMEOUT"
+	graphQLErrorClassInternalServerError = "INTERNAL_SERVER_ERROR"
+	graphQLErrorClassServerError         = "SERVER_ERROR"
+	graphQLErrorClassForbidden           = "FORBIDDEN"
+	graphQLErrorClassUnauthenticated     = "UNAUTHENTICATED"
+	graphQLErrorCodeBadAPIKey            = "BAD_API_KEY"
+)
+
+// retryableError is implemented by NerdGraph error responses, which report whether they are worth retrying,
+// e.g. because of a server side timeout.
+type retryableError interface {
+	IsRetryableError() bool
+}
+
+// classifiedError is implemented by errors produced by the client decorators, which know their class.
+type classifiedError interface {
+	ErrorClass() string
+}
+
+// classifyError returns class of the given error returned by the NRDB client.
+//
+//nolint:cyclop // Just a list of cases.
+func classifyError(err error) string {
+	var (
+		netErr           net.Error
+		apiStatus        apierrors.APIStatus
+		unauthorized     *nrErrors.UnauthorizedError
+		paymentRequired  *nrErrors.PaymentRequiredError
+		maxRetries       *nrErrors.MaxRetriesReached
+		unexpectedStatus *nrErrors.UnexpectedStatusCode
+		retryable        retryableError
+		classified       classifiedError
+	)
+
+	switch {
+	case errors.As(err, &classified):
+		return classified.ErrorClass()
+	case errors.As(err, &apiStatus):
+		// Queries rejected by the adapter itself, e.g. due to exceeded rate limit.
+		return errorClassRejected
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errorClassTimeout
+	case errors.As(err, &unauthorized):
+		return errorClassAuthentication
+	case errors.As(err, &paymentRequired):
+		return errorClassAuthorization
+	case errors.As(err, &maxRetries):
+		return errorClassServer
+	case errors.As(err, &unexpectedStatus):
+		return statusCodeClass(unexpectedStatus)
+	case errors.As(err, &retryable):
+		return graphQLErrorsClass(retryable)
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF), errors.As(err, &netErr):
+		return errorClassNetwork
+	default:
+		return errorClassUnknown
+	}
+}
+
+// statusCodeClass returns class of the error based on the HTTP status code. Status code is not exported
+// by the client, so it is parsed from the error message.
+func statusCodeClass(err *nrErrors.UnexpectedStatusCode) string {
+	var statusCode int
+	if _, scanErr := fmt.Sscanf(err.Error(), "%d response returned", &statusCode); scanErr != nil {
+		return errorClassUnknown
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return errorClassAuthentication
+	case statusCode == http.StatusForbidden:
+		return errorClassAuthorization
+	case statusCode == http.StatusTooManyRequests:
+		return errorClassRateLimited
+	case statusCode == http.StatusGatewayTimeout:
+		return errorClassTimeout
+	case statusCode >= http.StatusInternalServerError:
+		return errorClassServer
+	default:
+		return errorClassUnknown
+	}
+}
+
+// isTransient returns true for error classes, which are likely to succeed when retried.
+func isTransient(class string) bool {
+	switch class {
+	case errorClassTimeout, errorClassNetwork, errorClassServer, errorClassRateLimited:
+		return true
+	default:
+		return false
+	}
+}
+
+// graphQLError holds the fields of an error reported by NerdGraph. The client does not export its error
+// type, so errors are read from its JSON representation.
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		ErrorClass string `json:"errorClass"`
+		ErrorCode  string `json:"error_code"` //nolint:tagliatelle // Defined by NerdGraph.
+		Code       string `json:"code"`
+	} `json:"extensions"`
+}
+
+// graphQLErrorsClass returns class of the errors reported by NerdGraph. The most severe class wins,
+// as e.g. a query with syntax error will not succeed even when it times out.
+func graphQLErrorsClass(err retryableError) string {
+	classes := map[string]bool{}
+
+	for _, graphQLErr := range graphQLErrors(err) {
+		classes[graphQLErr.class()] = true
+	}
+
+	for _, class := range []string{
+		errorClassAuthentication, errorClassAuthorization, errorClassNRQLSyntax, errorClassRateLimited,
+		errorClassTimeout, errorClassServer,
+	} {
+		if classes[class] {
+			return class
+		}
+	}
+
+	if err.IsRetryableError() {
+		return errorClassServer
+	}
+
+	return errorClassQuery
+}
+
+func graphQLErrors(err retryableError) []graphQLError {
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		return nil
+	}
+
+	response := struct {
+		Errors []graphQLError `json:"errors"`
+	}{}
+
+	if unmarshalErr := json.Unmarshal(data, &response); unmarshalErr != nil {
+		return nil
+	}
+
+	return response.Errors
+}
+
+func (e graphQLError) class() string {
+	message := strings.ToLower(e.Message)
+
+	switch {
+	case e.Extensions.ErrorCode == graphQLErrorCodeBadAPIKey, e.Extensions.ErrorClass == graphQLErrorClassUnauthenticated,
+		e.Extensions.Code == graphQLErrorClassUnauthenticated:
+		return errorClassAuthentication
+	case e.Extensions.ErrorClass == graphQLErrorClassForbidden, e.Extensions.Code == graphQLErrorClassForbidden,
+		strings.Contains(message, "access denied"), strings.Contains(message, "not authorized"):
+		return errorClassAuthorization
+	case strings.Contains(message, "nrql syntax error"):
+		return errorClassNRQLSyntax
+	case e.Extensions.ErrorClass == tooManyRequestsErrorClass:
+		return errorClassRateLimited
+	case e.Extensions.ErrorClass == graphQLErrorClassTimeout:
+		return errorClassTimeout
+	case e.Extensions.ErrorClass == graphQLErrorClassInternalServerError,
+		e.Extensions.ErrorClass == graphQLErrorClassServerError:
+		return errorClassServer
+	default:
+		return errorClassQuery
+	}
+}
+
+// queryError reports the class of an error returned by the NRDB client and the matching Kubernetes API status,
+// so clients of the API can tell e.g. a revoked API key from a transient failure.
+type queryError struct {
+	err   error
+	class string
+}
+
+func (e *queryError) Error() string {
+	return fmt.Sprintf("%s error: %v", strings.ReplaceAll(e.class, "_", " "), e.err)
+}
+
+func (e *queryError) Unwrap() error {
+	return e.err
+}
+
+// ErrorClass reports the class of the error.
+func (e *queryError) ErrorClass() string {
+	return e.class
+}
+
+// Status implements apierrors.APIStatus.
+func (e *queryError) Status() metav1.Status {
+	code, reason := classStatus(e.class)
+
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    code,
+		Reason:  reason,
+		Message: e.Error(),
+	}
+}
+
+// classStatus returns the Kubernetes API status code and reason matching the given error class. Authentication
+// and authorization failures of the adapter against New Relic are reported as internal errors, as 401 and 403
+// statuses would tell clients of the API their own credentials were rejected. Their class is in the message.
+// Other query errors are reported as internal errors as well, as they are not known to be caused by the query.
+func classStatus(class string) (int32, metav1.StatusReason) {
+	switch class {
+	case errorClassNRQLSyntax:
+		return http.StatusBadRequest, metav1.StatusReasonBadRequest
+	case errorClassRateLimited, errorClassConcurrencyLimited:
+		return http.StatusTooManyRequests, metav1.StatusReasonTooManyRequests
+	case errorClassTimeout:
+		return http.StatusGatewayTimeout, metav1.StatusReasonTimeout
+	case errorClassServer, errorClassNetwork, errorClassCircuitOpen:
+		return http.StatusServiceUnavailable, metav1.StatusReasonServiceUnavailable
+	default:
+		return http.StatusInternalServerError, metav1.StatusReasonInternalError
+	}
+}
+
+// failedQueryError reports the query which caused the wrapped error, so the query can be shown to owners
+// of the requesting HPAs.
+type failedQueryError struct {
+	query Query
+	err   error
+}
+
+func (e *failedQueryError) Error() string {
+	return fmt.Sprintf("query %q: %v", e.query, e.err)
+}
+
+func (e *failedQueryError) Unwrap() error {
+	return e.err
+}
+
+// QueryFailure describes the failed query which caused an error returned by the provider.
+type QueryFailure struct {
+	// Query is the NRQL query as sent to New Relic, including filters added by the adapter.
+	Query string
+	// ErrorClass is the class of the error, e.g. "timeout" or "nrql_syntax".
+	ErrorClass string
+	// Err is the error of the query, without the query itself.
+	Err error
+}
+
+// QueryFailureFrom returns description of the failed query which caused the given error, if the error
+// was caused by a query.
+func QueryFailureFrom(err error) (QueryFailure, bool) {
+	var failedQuery *failedQueryError
+	if !errors.As(err, &failedQuery) {
+		return QueryFailure{}, false
+	}
+
+	return QueryFailure{
+		Query:      string(failedQuery.query),
+		ErrorClass: classifyError(failedQuery.err),
+		Err:        failedQuery.err,
+	}, true
+}
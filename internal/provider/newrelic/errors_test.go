@@ -0,0 +1,163 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	nrErrors "github.com/newrelic/newrelic-client-go/v2/pkg/errors"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen // Just a large test suite.
+func Test_Getting_external_metric_returns_API_status_matching_class_of_query_error(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	cases := map[string]struct {
+		err   error
+		code  int32
+		class string
+	}{
+		"internal_error_for_invalid_api_key": {
+			err:   nrErrors.NewUnauthorizedError(),
+			code:  http.StatusInternalServerError,
+			class: "authentication",
+		},
+		"internal_error_for_bad_api_key_error_code": {
+			err:   newGraphQLError("Invalid API key", "", "BAD_API_KEY"),
+			code:  http.StatusInternalServerError,
+			class: "authentication",
+		},
+		"internal_error_for_denied_account_access": {
+			err:   newGraphQLError("Access denied for account 1", "FORBIDDEN", ""),
+			code:  http.StatusInternalServerError,
+			class: "authorization",
+		},
+		"bad_request_for_nrql_syntax_error": {
+			err:   newGraphQLError("NRQL Syntax Error: Error at line 1 position 8, unexpected 'x'", "", ""),
+			code:  http.StatusBadRequest,
+			class: "nrql_syntax",
+		},
+		"internal_error_for_other_query_error": {
+			err:   newGraphQLError("Unexpected failure", "", ""),
+			code:  http.StatusInternalServerError,
+			class: "query",
+		},
+		"too_many_requests_for_rate_limited_query": {
+			err:   newGraphQLError("Too many requests", "TOO_MANY_REQUESTS", ""),
+			code:  http.StatusTooManyRequests,
+			class: "rate_limited",
+		},
+		"gateway_timeout_for_timed_out_query": {
+			err:   newGraphQLError("NRDB query timed out", "TIMEOUT", ""),
+			code:  http.StatusGatewayTimeout,
+			class: "timeout",
+		},
+		"service_unavailable_for_server_error": {
+			err:   nrErrors.NewUnexpectedStatusCode(503, "service unavailable"),
+			code:  http.StatusServiceUnavailable,
+			class: "server",
+		},
+		"internal_error_for_unknown_error": {
+			err:   fmt.Errorf("new error"),
+			code:  http.StatusInternalServerError,
+			class: "unknown",
+		},
+	}
+
+	for testCaseName, testCase := range cases {
+		testCase := testCase
+
+		t.Run(testCaseName, func(t *testing.T) {
+			t.Parallel()
+
+			options, client := testProviderOptions()
+			client.err = testCase.err
+			client.response = nil
+
+			registry := metrics.NewKubeRegistry()
+			options.RegisterFunc = registry.Register
+
+			p := testProvider(t, options)
+
+			_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if code := responsewriters.ErrorToAPIStatus(err).Code; code != testCase.code {
+				t.Errorf("Expected API status code %d, got %d: %v", testCase.code, code, err)
+			}
+
+			failure, ok := newrelic.QueryFailureFrom(err)
+			if !ok || failure.Query != client.query || failure.ErrorClass != testCase.class || failure.Err == nil {
+				t.Errorf("Expected failure of query %q with %q error, got %+v", client.query, testCase.class, failure)
+			}
+
+			// Errors of unknown class are returned as they are.
+			class := strings.ReplaceAll(testCase.class, "_", " ")
+			if testCase.class != "unknown" && !strings.Contains(err.Error(), class) {
+				t.Errorf("Expected error message to contain %q error class, got: %v", testCase.class, err)
+			}
+
+			expected := fmt.Sprintf(`
+			# HELP newrelic_adapter_external_provider_queries_total [ALPHA] Total number of queries to the NewRelic backend.
+			# TYPE newrelic_adapter_external_provider_queries_total counter
+			newrelic_adapter_external_provider_queries_total{metric="test_metric",result=%q,source="query"} 1
+			`, testCase.class)
+
+			metricName := "newrelic_adapter_external_provider_queries_total"
+
+			if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+				t.Fatalf("Unexpected metric value: %v", err)
+			}
+		})
+	}
+}
+
+// graphQLError mimics the error type returned by the client for errors reported by NerdGraph.
+type graphQLError struct {
+	Errors []graphQLErrorEntry `json:"errors"`
+}
+
+type graphQLErrorEntry struct {
+	Message    string                 `json:"message"`
+	Extensions graphQLErrorExtensions `json:"extensions"`
+}
+
+type graphQLErrorExtensions struct {
+	ErrorClass string `json:"errorClass"`
+	ErrorCode  string `json:"error_code"` //nolint:tagliatelle // Defined by NerdGraph.
+}
+
+func newGraphQLError(message, errorClass, errorCode string) *graphQLError {
+	return &graphQLError{
+		Errors: []graphQLErrorEntry{
+			{
+				Message:    message,
+				Extensions: graphQLErrorExtensions{ErrorClass: errorClass, ErrorCode: errorCode},
+			},
+		},
+	}
+}
+
+func (e *graphQLError) Error() string {
+	return e.Errors[0].Message
+}
+
+func (e *graphQLError) IsRetryableError() bool {
+	return e.Errors[0].Extensions.ErrorClass == "TIMEOUT"
+}
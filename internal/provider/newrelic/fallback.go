@@ -0,0 +1,254 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+// Sources of values served by the provider.
+const (
+	sourceQuery         = "query"
+	sourceFallbackValue = "fallback_value"
+	sourceFallbackQuery = "fallback_query"
+	sourceLastKnownGood = "last_known_good"
+
+	// SourceLabel is the label added to values served by a fallback, holding the source of the value.
+	SourceLabel = "source"
+
+	// DefaultLastKnownGoodMaxAge is the maximum age of the value served by the last known good fallback
+	// when none is configured.
+	DefaultLastKnownGoodMaxAge = time.Hour
+
+	// knownValuesEvictionInterval is the minimum interval between evictions of expired known values.
+	knownValuesEvictionInterval = time.Minute
+)
+
+// Fallback holds the config of a value served when the query of a metric fails. Exactly one of the fields
+// must be set.
+type Fallback struct {
+	// Value is a static value.
+	Value *float64 `json:"value"`
+	// Query is a secondary query, e.g. against a different event type or account.
+	Query Query `json:"query"`
+	// AccountID is the account the secondary query is executed for. Defaults to the configured account.
+	AccountID           int64 `json:"accountID"`
+	RemoveClusterFilter bool  `json:"removeClusterFilter"`
+	// LastKnownGood serves the last value fetched by the query of the metric for the same selector.
+	LastKnownGood bool `json:"lastKnownGood"`
+	// MaxAgeSeconds is the maximum age of the value served by the last known good fallback.
+	// Defaults to DefaultLastKnownGoodMaxAge.
+	MaxAgeSeconds int64 `json:"maxAgeSeconds"`
+}
+
+func (f Fallback) validate() error {
+	set := 0
+
+	for _, isSet := range []bool{f.Value != nil, f.Query != "", f.LastKnownGood} {
+		if isSet {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return fmt.Errorf("exactly one of value, query and lastKnownGood must be set")
+	}
+
+	if f.AccountID != 0 && f.Query == "" {
+		return fmt.Errorf("accountID can be set only together with query")
+	}
+
+	if f.MaxAgeSeconds != 0 && !f.LastKnownGood {
+		return fmt.Errorf("maxAgeSeconds can be set only together with lastKnownGood")
+	}
+
+	if f.MaxAgeSeconds < 0 {
+		return fmt.Errorf("maxAgeSeconds may not be negative, got %d", f.MaxAgeSeconds)
+	}
+
+	return nil
+}
+
+func (f Fallback) maxAge() time.Duration {
+	if f.MaxAgeSeconds == 0 {
+		return DefaultLastKnownGoodMaxAge
+	}
+
+	return time.Duration(f.MaxAgeSeconds) * time.Second
+}
+
+// lastKnownGoodMaxAge returns the longest maximum age of the last known good fallbacks of the metric, or zero
+// if the metric has none, so values are kept only as long as some fallback may serve them.
+func (m Metric) lastKnownGoodMaxAge() time.Duration {
+	var maxAge time.Duration
+
+	for _, fallback := range m.Fallbacks {
+		if fallback.LastKnownGood {
+			maxAge = max(maxAge, fallback.maxAge())
+		}
+	}
+
+	return maxAge
+}
+
+// knownValue is a value fetched by the query of a metric.
+type knownValue struct {
+	value     float64
+	timestamp *time.Time
+	fetchedAt time.Time
+	// expiresAt is the time after which no fallback of the metric serves the value, so it can be evicted.
+	expiresAt time.Time
+}
+
+// knownValues holds the last value fetched by the query of each metric and selector. Expired values are
+// evicted periodically, so values of selectors no longer requested do not pile up.
+type knownValues struct {
+	mu        sync.Mutex
+	values    map[string]knownValue
+	lastEvict time.Time
+}
+
+func newKnownValues() *knownValues {
+	return &knownValues{
+		values: map[string]knownValue{},
+	}
+}
+
+func (k *knownValues) store(key string, value knownValue, now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.values[key] = value
+
+	if now.Sub(k.lastEvict) < knownValuesEvictionInterval {
+		return
+	}
+
+	k.lastEvict = now
+
+	for key, value := range k.values {
+		if now.After(value.expiresAt) {
+			delete(k.values, key)
+		}
+	}
+}
+
+// load returns the value stored under the given key, unless it is older than the given maximum age.
+func (k *knownValues) load(key string, maxAge time.Duration, now time.Time) (knownValue, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	value, ok := k.values[key]
+	if !ok || now.Sub(value.fetchedAt) > maxAge {
+		return knownValue{}, false
+	}
+
+	return value, true
+}
+
+func lastKnownGoodKey(name string, sl labels.Selector) string {
+	if sl == nil {
+		return name
+	}
+
+	return fmt.Sprintf("%s/%s", name, sl)
+}
+
+// fallbackQuerySource returns the source under which queries of the given fallback are accounted, so e.g.
+// a failing fallback query does not open the circuit breaker of the metric query and a successful one does
+// not hide failures of the metric query.
+func fallbackQuerySource(index int) string {
+	return fmt.Sprintf("%s_%d", sourceFallbackQuery, index)
+}
+
+// canFallBack returns true when the given error was caused by the query of the metric or the backend, as
+// e.g. a selector which cannot be turned into a query filter fails fallback queries as well.
+func canFallBack(err error) bool {
+	var failedQuery *failedQueryError
+
+	return errors.As(err, &failedQuery)
+}
+
+// fallback tries the fallbacks of the metric in order and returns the first available value, or the error of
+// the query if none is available.
+func (p *directProvider) fallback(
+	ctx context.Context, namespace, name string, metric Metric, sl labels.Selector, queryErr error,
+) (float64, *time.Time, string, error) {
+	if !canFallBack(queryErr) {
+		return 0, nil, "", queryErr
+	}
+
+	for i, fallback := range metric.Fallbacks {
+		value, timestamp, source, err := p.fallbackValue(ctx, namespace, name, i, metric, fallback, sl)
+		if err != nil {
+			klog.ErrorS(err, "Fallback of metric failed", logkeys.Metric, name, logkeys.Namespace, namespace,
+				logkeys.Fallback, i)
+
+			continue
+		}
+
+		klog.ErrorS(queryErr, "Serving fallback value, as the query failed", logkeys.Metric, name,
+			logkeys.Namespace, namespace, logkeys.Source, source)
+
+		p.metrics.fallbacksTotal.WithLabelValues(name, source).Inc()
+
+		return value, timestamp, source, nil
+	}
+
+	return 0, nil, "", queryErr
+}
+
+func (p *directProvider) fallbackValue(
+	ctx context.Context, namespace, name string, index int, metric Metric, fallback Fallback, sl labels.Selector,
+) (float64, *time.Time, string, error) {
+	switch {
+	case fallback.Value != nil:
+		return *fallback.Value, nil, sourceFallbackValue, nil
+	case fallback.LastKnownGood:
+		value, ok := p.lastKnownGood.load(lastKnownGoodKey(name, sl), fallback.maxAge(), time.Now())
+		if !ok {
+			return 0, nil, "", fmt.Errorf("no value fetched within %v", fallback.maxAge())
+		}
+
+		return value.value, value.timestamp, sourceLastKnownGood, nil
+	default:
+		accountID := fallback.AccountID
+		if accountID == 0 {
+			accountID = p.accountID
+		}
+
+		fallbackMetric := Metric{
+			Query:               fallback.Query,
+			RemoveClusterFilter: fallback.RemoveClusterFilter,
+			OldestSampleAllowed: metric.OldestSampleAllowed,
+			source:              fallbackQuerySource(index),
+		}
+
+		value, timestamp, err := p.executeQuery(ctx, namespace, name, fallbackMetric, accountID, sl)
+		if err != nil {
+			return 0, nil, "", err
+		}
+
+		return value, timestamp, sourceFallbackQuery, nil
+	}
+}
+
+// sourceLabels returns labels of a value served from the given source. Values fetched by the query
+// of the metric are served without labels.
+func sourceLabels(source string) map[string]string {
+	if source == sourceQuery {
+		return map[string]string{}
+	}
+
+	return map[string]string{SourceLabel: source}
+}
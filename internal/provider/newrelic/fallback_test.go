@@ -0,0 +1,303 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const failingQuery = "select failing from testSample"
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_with_fallbacks(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("returns_static_value_with_source_label_when_query_fails", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options := fallbackProviderOptions(newrelic.Fallback{Value: ptr.To(42.0)})
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		result, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if value := result.Items[0].Value.AsApproximateFloat64(); value != 42 {
+			t.Errorf("Expected value 42, got %v", value)
+		}
+
+		if source := result.Items[0].MetricLabels[newrelic.SourceLabel]; source != "fallback_value" {
+			t.Errorf("Expected source label %q, got %q", "fallback_value", source)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_fallbacks_total [ALPHA] Total number of values served from a fallback as the query to the NewRelic backend failed.
+		# TYPE newrelic_adapter_external_provider_fallbacks_total counter
+		newrelic_adapter_external_provider_fallbacks_total{metric="test_metric",source="fallback_value"} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_fallbacks_total"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("tries_fallbacks_in_order_until_one_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		p := testProvider(t, fallbackProviderOptions(
+			newrelic.Fallback{LastKnownGood: true},
+			newrelic.Fallback{Query: failingQuery},
+			newrelic.Fallback{Query: testQuery},
+			newrelic.Fallback{Value: ptr.To(42.0)},
+		))
+
+		result, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if value := result.Items[0].Value.AsApproximateFloat64(); value != 1 {
+			t.Errorf("Expected value 1, got %v", value)
+		}
+
+		if source := result.Items[0].MetricLabels[newrelic.SourceLabel]; source != "fallback_query" {
+			t.Errorf("Expected source label %q, got %q", "fallback_query", source)
+		}
+	})
+
+	t.Run("returns_last_value_fetched_by_query_when_query_fails", func(t *testing.T) {
+		t.Parallel()
+
+		client := &metricFailingClient{}
+
+		options := fallbackProviderOptions()
+		options.NRDBClient = client
+		options.ExternalMetrics[testMetricName] = newrelic.Metric{
+			Query:     testQuery,
+			Fallbacks: []newrelic.Fallback{{LastKnownGood: true}},
+		}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		client.failAll = true
+
+		result, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if source := result.Items[0].MetricLabels[newrelic.SourceLabel]; source != "last_known_good" {
+			t.Errorf("Expected source label %q, got %q", "last_known_good", source)
+		}
+	})
+
+	t.Run("does_not_return_last_value_older_than_max_age", func(t *testing.T) {
+		t.Parallel()
+
+		client := &metricFailingClient{}
+
+		options := fallbackProviderOptions()
+		options.NRDBClient = client
+		options.ExternalMetrics[testMetricName] = newrelic.Metric{
+			Query:     testQuery,
+			Fallbacks: []newrelic.Fallback{{LastKnownGood: true, MaxAgeSeconds: 1}},
+		}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		client.failAll = true
+
+		time.Sleep(1100 * time.Millisecond)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+	})
+
+	t.Run("does_not_fall_back_when_query_cannot_be_built_for_selector", func(t *testing.T) {
+		t.Parallel()
+
+		p := testProvider(t, fallbackProviderOptions(newrelic.Fallback{Value: ptr.To(42.0)}))
+
+		selector, err := labels.Parse("key>1")
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		_, err = p.GetExternalMetric(ctx, "", selector, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsBadRequest(err) {
+			t.Fatalf("Expected bad request error, got: %v", err)
+		}
+	})
+
+	t.Run("accounts_fallback_queries_separately_from_metric_query", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options := fallbackProviderOptions(newrelic.Fallback{Query: testQuery})
+		options.CircuitBreaker = newrelic.CircuitBreakerOptions{FailureThreshold: 2, OpenDuration: time.Hour}
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		for i := 0; i < 2; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_circuit_breaker_state [ALPHA] State of the circuit breaker guarding queries to the NewRelic backend: 0 closed, 1 half-open, 2 open.
+		# TYPE newrelic_adapter_external_provider_circuit_breaker_state gauge
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="",scope="connection",source=""} 0
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="test_metric",scope="metric",source="query"} 2
+		newrelic_adapter_external_provider_circuit_breaker_state{metric="test_metric",scope="metric",source="fallback_query_0"} 0
+		`
+
+		metricName := "newrelic_adapter_external_provider_circuit_breaker_state"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("records_fallback_queries_under_their_source_without_touching_status_of_metric_query", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options := fallbackProviderOptions(newrelic.Fallback{Query: testQuery})
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for _, labels := range []map[string]string{
+			{"metric": testMetricName, "source": "query", "result": "timeout"},
+			{"metric": testMetricName, "source": "fallback_query_0", "result": "ok"},
+		} {
+			histogram, err := metricsTestutil.GetHistogramVecFromGatherer(registry,
+				"newrelic_adapter_external_provider_query_duration_seconds", labels)
+			if err != nil {
+				t.Fatalf("Unexpected error while gathering query duration metric: %v", err)
+			}
+
+			if count := histogram.GetAggregatedSampleCount(); count != 1 {
+				t.Errorf("Expected 1 query with labels %v, got %d", labels, count)
+			}
+		}
+
+		status := newrelic.MetricStatuses(p)[0]
+
+		if status.LastQuery == "" || !strings.HasPrefix(status.LastQuery, failingQuery) {
+			t.Errorf("Expected last query to be query of the metric, got %q", status.LastQuery)
+		}
+
+		if len(status.LatencySeconds) != 0 {
+			t.Errorf("Expected no latency of successful fallback query, got %v", status.LatencySeconds)
+		}
+	})
+
+	t.Run("applies_rate_limit_of_metric_to_fallback_queries", func(t *testing.T) {
+		t.Parallel()
+
+		options := fallbackProviderOptions()
+		options.ExternalMetrics[testMetricName] = newrelic.Metric{
+			Query:     failingQuery,
+			RateLimit: newrelic.RateLimit{QueriesPerMinute: 1, Burst: 2},
+			Fallbacks: []newrelic.Fallback{{Query: testQuery}},
+		}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected too many requests error, got: %v", err)
+		}
+	})
+
+	t.Run("returns_query_error_when_all_fallbacks_fail", func(t *testing.T) {
+		t.Parallel()
+
+		p := testProvider(t, fallbackProviderOptions(
+			newrelic.Fallback{LastKnownGood: true},
+			newrelic.Fallback{Query: failingQuery},
+		))
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+	})
+
+	t.Run("returns_value_without_source_label_when_query_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		options := fallbackProviderOptions()
+		options.ExternalMetrics[testMetricName] = newrelic.Metric{
+			Query:     testQuery,
+			Fallbacks: []newrelic.Fallback{{Value: ptr.To(42.0)}},
+		}
+
+		p := testProvider(t, options)
+
+		result, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(result.Items[0].MetricLabels) != 0 {
+			t.Errorf("Expected no labels, got %v", result.Items[0].MetricLabels)
+		}
+	})
+}
+
+// fallbackProviderOptions returns options of a provider with test metric, which query always fails.
+func fallbackProviderOptions(fallbacks ...newrelic.Fallback) newrelic.ProviderOptions {
+	options, _ := testProviderOptions()
+	options.NRDBClient = &metricFailingClient{}
+	options.ExternalMetrics[testMetricName] = newrelic.Metric{
+		Query:     failingQuery,
+		Fallbacks: fallbacks,
+	}
+
+	return options
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apiserver/pkg/server/healthz"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+)
+
+// DefaultHealthMaxQueryAge is the period without a successful query after which failing queries are reported
+// by health checks when none is configured.
+const DefaultHealthMaxQueryAge = 10 * time.Minute
+
+// HealthOptions holds the configuration of health checks of the direct provider. Checks of queries pass unless
+// failing on the given kind of errors is enabled. The configuration check always fails when no metric can be
+// served, as the configuration cannot change without restarting the adapter.
+//
+// Failed readiness removes the replica from the Service, so no more queries are sent through it to notice
+// recovery. Failures are therefore reported only for MaxQueryAge after the latest failed query, after which
+// the replica serves queries again.
+type HealthOptions struct {
+	// MaxQueryAge is the period without a successful query after which failing queries are reported. Failures
+	// are reported for the same period after the latest failed query.
+	MaxQueryAge time.Duration
+	// FailOnRejectedAPIKey makes the API key check fail when the API key or account is rejected by New Relic.
+	FailOnRejectedAPIKey bool
+	// FailOnOutage makes the connectivity check fail when New Relic is unreachable, i.e. when the circuit
+	// breaker of the connection is open or no query succeeded within MaxQueryAge.
+	FailOnOutage bool
+	// FailOnMetricErrors makes the check of a metric fail when its query keeps failing, e.g. because of
+	// invalid NRQL.
+	FailOnMetricErrors bool
+}
+
+// queryHealth holds results of recent queries.
+type queryHealth struct {
+	lastSuccess    time.Time
+	lastFailure    time.Time
+	lastErrorClass string
+	lastError      string
+}
+
+// failing returns true when the latest query failed within the given period and no query succeeded within it.
+func (h queryHealth) failing(now time.Time, maxQueryAge time.Duration) bool {
+	return h.failedRecently(now, maxQueryAge) && now.Sub(h.lastSuccess) > maxQueryAge
+}
+
+// failedRecently returns true when the latest query failed within the given period.
+func (h queryHealth) failedRecently(now time.Time, maxQueryAge time.Duration) bool {
+	return h.lastFailure.After(h.lastSuccess) && now.Sub(h.lastFailure) <= maxQueryAge
+}
+
+func (h queryHealth) String() string {
+	lastSuccess := "never"
+	if !h.lastSuccess.IsZero() {
+		lastSuccess = h.lastSuccess.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("last query failed with %s error: %s, last successful query: %s",
+		h.lastErrorClass, h.lastError, lastSuccess)
+}
+
+// healthRecorder records results of queries of each metric, so health checks can report them.
+type healthRecorder struct {
+	lock    sync.Mutex
+	overall queryHealth
+	metrics map[string]queryHealth
+}
+
+func newHealthRecorder() *healthRecorder {
+	return &healthRecorder{metrics: map[string]queryHealth{}}
+}
+
+func (r *healthRecorder) recordSuccess(name string, now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	metric := r.metrics[name]
+	metric.lastSuccess = now
+	r.metrics[name] = metric
+
+	r.overall.lastSuccess = now
+}
+
+func (r *healthRecorder) recordFailure(name string, err error, now time.Time) {
+	class := classifyError(err)
+	if failure, ok := QueryFailureFrom(err); ok {
+		class = failure.ErrorClass
+	}
+
+	// Queries rejected by the adapter itself say nothing about the health of New Relic or of the query.
+	switch class {
+	case errorClassRejected, errorClassCircuitOpen, errorClassConcurrencyLimited:
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	failed := queryHealth{lastFailure: now, lastErrorClass: class, lastError: err.Error()}
+
+	metric := r.metrics[name]
+	failed.lastSuccess = metric.lastSuccess
+	r.metrics[name] = failed
+
+	failed.lastSuccess = r.overall.lastSuccess
+	r.overall = failed
+}
+
+func (r *healthRecorder) metric(name string) queryHealth {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.metrics[name]
+}
+
+func (r *healthRecorder) overallHealth() queryHealth {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.overall
+}
+
+// HealthChecks returns checks reporting whether the configuration allows serving metrics, whether the API key
+// is accepted by New Relic, whether New Relic is reachable and whether the query of each configured metric
+// succeeds. Each metric is checked separately,
+// so its health is listed by verbose output of the health endpoints. If the given provider is not a direct
+// provider, nil is returned.
+func HealthChecks(p provider.ExternalMetricsProvider, options HealthOptions) []healthz.HealthChecker {
+	dp, ok := p.(*directProvider)
+	if !ok {
+		return nil
+	}
+
+	if options.MaxQueryAge <= 0 {
+		options.MaxQueryAge = DefaultHealthMaxQueryAge
+	}
+
+	checks := []healthz.HealthChecker{
+		healthz.NamedCheck("newrelic-configuration", func(_ *http.Request) error {
+			return dp.checkConfiguration()
+		}),
+		healthz.NamedCheck("newrelic-api-key", func(_ *http.Request) error {
+			return dp.checkAPIKey(options, time.Now())
+		}),
+		healthz.NamedCheck("newrelic-connectivity", func(_ *http.Request) error {
+			return dp.checkConnectivity(options, time.Now())
+		}),
+	}
+
+	names := make([]string, 0, len(dp.metricsSupported))
+	for name := range dp.metricsSupported {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		name := name
+
+		checks = append(checks, healthz.NamedCheck("external-metric-"+name, func(_ *http.Request) error {
+			return dp.checkMetric(name, options, time.Now())
+		}))
+	}
+
+	return checks
+}
+
+// checkConfiguration fails when no metric can be served, i.e. when no account is set, no metric is configured
+// or a metric has no query.
+func (p *directProvider) checkConfiguration() error {
+	if p.accountID == 0 {
+		return fmt.Errorf("no account to execute queries for is configured")
+	}
+
+	if len(p.metricsSupported) == 0 {
+		return fmt.Errorf("no external metrics are configured")
+	}
+
+	names := make([]string, 0, len(p.metricsSupported))
+	for name := range p.metricsSupported {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.TrimSpace(string(p.metricsSupported[name].Query)) == "" {
+			return fmt.Errorf("metric %q has no query configured", name)
+		}
+	}
+
+	return nil
+}
+
+// checkAPIKey fails when the latest query was rejected because of the API key or account, as no query
+// will succeed until the configuration is fixed.
+func (p *directProvider) checkAPIKey(options HealthOptions, now time.Time) error {
+	if !options.FailOnRejectedAPIKey {
+		return nil
+	}
+
+	health := p.health.overallHealth()
+
+	switch {
+	case !health.failedRecently(now, options.MaxQueryAge):
+		return nil
+	case health.lastErrorClass == errorClassAuthentication, health.lastErrorClass == errorClassAuthorization:
+		return fmt.Errorf("API key or account rejected by New Relic: %s", health)
+	default:
+		return nil
+	}
+}
+
+// checkConnectivity fails while the circuit breaker of the connection is open or when queries recently
+// failed with transient errors and none succeeded within MaxQueryAge.
+func (p *directProvider) checkConnectivity(options HealthOptions, now time.Time) error {
+	if !options.FailOnOutage {
+		return nil
+	}
+
+	if p.breakers != nil && p.breakers.connection.isOpen() {
+		return fmt.Errorf("circuit breaker of New Relic connection is open")
+	}
+
+	if health := p.health.overallHealth(); health.failing(now, options.MaxQueryAge) && isTransient(health.lastErrorClass) {
+		return fmt.Errorf("no query succeeded within %v: %s", options.MaxQueryAge, health)
+	}
+
+	return nil
+}
+
+func (p *directProvider) checkMetric(name string, options HealthOptions, now time.Time) error {
+	if !options.FailOnMetricErrors {
+		return nil
+	}
+
+	if p.breakers != nil && p.breakers.metricOpen(name) {
+		return fmt.Errorf("circuit breaker of metric %q is open", name)
+	}
+
+	if health := p.health.metric(name); health.failing(now, options.MaxQueryAge) {
+		return fmt.Errorf("no query of metric %q succeeded within %v: %s", name, options.MaxQueryAge, health)
+	}
+
+	return nil
+}
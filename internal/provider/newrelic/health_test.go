@@ -0,0 +1,272 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	nrErrors "github.com/newrelic/newrelic-client-go/v2/pkg/errors"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Health_checks(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("include_check_of_each_configured_metric", func(t *testing.T) {
+		t.Parallel()
+
+		options, _ := testProviderOptions()
+		options.ExternalMetrics["other_metric"] = newrelic.Metric{Query: testQuery}
+
+		checks := newrelic.HealthChecks(testProvider(t, options), newrelic.HealthOptions{})
+
+		for _, name := range []string{
+			"newrelic-configuration", "newrelic-api-key", "newrelic-connectivity", "external-metric-other_metric",
+			"external-metric-test_metric",
+		} {
+			if check(t, checks, name) == nil {
+				t.Errorf("Expected check %q", name)
+			}
+		}
+	})
+
+	t.Run("fail_configuration_check_when_no_metric_can_be_served", func(t *testing.T) {
+		t.Parallel()
+
+		for name, metrics := range map[string]map[string]newrelic.Metric{
+			"no_metrics":           {},
+			"metric_without_query": {testMetricName: {Query: testQuery}, "other_metric": {Query: " "}},
+			"configured_metric":    {testMetricName: {Query: testQuery}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				options, _ := testProviderOptions()
+				options.ExternalMetrics = metrics
+
+				err := check(t, newrelic.HealthChecks(testProvider(t, options), newrelic.HealthOptions{}),
+					"newrelic-configuration").Check(nil)
+
+				if valid := name == "configured_metric"; valid != (err == nil) {
+					t.Fatalf("Expected check to pass only for valid configuration, got: %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("fail_when_API_key_is_rejected_until_query_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		options, client := testProviderOptions()
+		response := client.response
+		client.err = nrErrors.NewUnauthorizedError()
+		client.response = nil
+
+		p := testProvider(t, options)
+		apiKeyCheck := check(t, newrelic.HealthChecks(p, newrelic.HealthOptions{FailOnRejectedAPIKey: true}),
+			"newrelic-api-key")
+
+		if err := apiKeyCheck.Check(nil); err != nil {
+			t.Fatalf("Expected check to pass before any query, got: %v", err)
+		}
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if err := apiKeyCheck.Check(nil); err == nil {
+			t.Fatalf("Expected check to fail after query rejected with invalid API key")
+		}
+
+		client.err = nil
+		client.response = response
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := apiKeyCheck.Check(nil); err != nil {
+			t.Fatalf("Expected check to pass after successful query, got: %v", err)
+		}
+	})
+
+	t.Run("pass_when_API_key_is_rejected_unless_configured", func(t *testing.T) {
+		t.Parallel()
+
+		options, client := testProviderOptions()
+		client.err = nrErrors.NewUnauthorizedError()
+		client.response = nil
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if err := check(t, newrelic.HealthChecks(p, newrelic.HealthOptions{}), "newrelic-api-key").Check(nil); err != nil {
+			t.Fatalf("Expected check to pass, got: %v", err)
+		}
+	})
+
+	t.Run("fail_on_outage_only_when_configured", func(t *testing.T) {
+		t.Parallel()
+
+		options, client := testProviderOptions()
+		client.err = nrErrors.NewUnexpectedStatusCode(http.StatusServiceUnavailable, "service unavailable")
+		client.response = nil
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		// No query has ever succeeded, so the default maximum query age is exceeded.
+		ignoringOutage := newrelic.HealthChecks(p, newrelic.HealthOptions{})
+
+		for _, name := range []string{"newrelic-connectivity", "external-metric-test_metric"} {
+			if err := check(t, ignoringOutage, name).Check(nil); err != nil {
+				t.Errorf("Expected check %q to ignore outage, got: %v", name, err)
+			}
+		}
+
+		failingOnOutage := newrelic.HealthChecks(p, newrelic.HealthOptions{
+			FailOnOutage:       true,
+			FailOnMetricErrors: true,
+		})
+
+		for _, name := range []string{"newrelic-connectivity", "external-metric-test_metric"} {
+			if err := check(t, failingOnOutage, name).Check(nil); err == nil {
+				t.Errorf("Expected check %q to fail on outage", name)
+			}
+		}
+	})
+
+	t.Run("fail_connectivity_when_circuit_breaker_is_open", func(t *testing.T) {
+		t.Parallel()
+
+		options, client := testProviderOptions()
+		client.err = nrErrors.NewUnexpectedStatusCode(http.StatusServiceUnavailable, "service unavailable")
+		client.response = nil
+		options.CircuitBreaker = newrelic.CircuitBreakerOptions{FailureThreshold: 1, OpenDuration: time.Hour}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		// Default maximum query age is not exceeded, so only the open breaker fails the check.
+		checks := newrelic.HealthChecks(p, newrelic.HealthOptions{FailOnOutage: true})
+
+		if err := check(t, checks, "newrelic-connectivity").Check(nil); err == nil {
+			t.Fatalf("Expected check to fail when circuit breaker is open")
+		}
+	})
+
+	t.Run("recover_without_queries_after_maximum_query_age", func(t *testing.T) {
+		t.Parallel()
+
+		maxQueryAge := 500 * time.Millisecond
+
+		for name, err := range map[string]error{
+			"newrelic-api-key":            nrErrors.NewUnauthorizedError(),
+			"newrelic-connectivity":       nrErrors.NewUnexpectedStatusCode(http.StatusServiceUnavailable, "unavailable"),
+			"external-metric-test_metric": newGraphQLError("NRQL Syntax Error: unexpected 'x'", "", ""),
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				options, client := testProviderOptions()
+				client.err = err
+				client.response = nil
+				options.CircuitBreaker = newrelic.CircuitBreakerOptions{FailureThreshold: 1, OpenDuration: maxQueryAge}
+
+				p := testProvider(t, options)
+
+				if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+					t.Fatalf("Expected error")
+				}
+
+				checker := check(t, newrelic.HealthChecks(p, newrelic.HealthOptions{
+					MaxQueryAge:          maxQueryAge,
+					FailOnRejectedAPIKey: true,
+					FailOnOutage:         true,
+					FailOnMetricErrors:   true,
+				}), name)
+
+				if err := checker.Check(nil); err == nil {
+					t.Fatalf("Expected check to fail")
+				}
+
+				// Replica failing readiness receives no queries, so the check must recover on its own.
+				for err := checker.Check(nil); err != nil; err = checker.Check(nil) {
+					select {
+					case <-ctx.Done():
+						t.Fatalf("Check did not recover: %v", err)
+					case <-time.After(10 * time.Millisecond):
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("pass_for_metric_failing_within_maximum_query_age", func(t *testing.T) {
+		t.Parallel()
+
+		options, client := testProviderOptions()
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		client.err = newGraphQLError("NRQL Syntax Error: Error at line 1 position 8, unexpected 'x'", "", "")
+		client.response = nil
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		checks := newrelic.HealthChecks(p, newrelic.HealthOptions{MaxQueryAge: time.Hour, FailOnMetricErrors: true})
+
+		if err := check(t, checks, "external-metric-test_metric").Check(nil); err != nil {
+			t.Fatalf("Expected check to pass, got: %v", err)
+		}
+	})
+}
+
+func Test_Health_checks_are_not_returned_for_other_providers(t *testing.T) {
+	t.Parallel()
+
+	if checks := newrelic.HealthChecks(&mock.Provider{}, newrelic.HealthOptions{}); checks != nil {
+		t.Fatalf("Expected no checks, got %v", checks)
+	}
+}
+
+// check returns check of the given name.
+func check(t *testing.T, checks []healthz.HealthChecker, name string) healthz.HealthChecker {
+	t.Helper()
+
+	for _, c := range checks {
+		if c.Name() == name {
+			return c
+		}
+	}
+
+	t.Fatalf("Check %q not found", name)
+
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// hedgeLatencySamples is the number of latest successful query latencies kept per metric.
+	hedgeLatencySamples = 100
+	// hedgeMinLatencySamples is the number of latencies needed before the observed percentile is used.
+	hedgeMinLatencySamples = 10
+)
+
+// HedgeOptions holds the configuration of hedging slow queries.
+type HedgeOptions struct {
+	// Delay is the time after which a duplicate of a query which has not returned yet is sent. When
+	// LatencyPercentile is set, Delay is used only until enough latencies of the metric are observed.
+	Delay time.Duration
+	// LatencyPercentile makes the duplicate to be sent after the given percentile of observed latencies
+	// of the metric, e.g. 95.
+	LatencyPercentile float64
+}
+
+func (o HedgeOptions) enabled() bool {
+	return o.Delay > 0 || o.LatencyPercentile > 0
+}
+
+func (o HedgeOptions) validate() error {
+	if o.Delay < 0 {
+		return fmt.Errorf("delay may not be negative, got %v", o.Delay)
+	}
+
+	if o.LatencyPercentile < 0 || o.LatencyPercentile >= 100 {
+		return fmt.Errorf("latency percentile must be in range [0, 100), got %v", o.LatencyPercentile)
+	}
+
+	return nil
+}
+
+// latencyWindow keeps the latest latencies of successful queries.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (w *latencyWindow) record(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < hedgeLatencySamples {
+		w.samples = append(w.samples, latency)
+
+		return
+	}
+
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % hedgeLatencySamples
+}
+
+// percentile returns the given percentile of kept latencies, if at least the given number of them
+// were observed.
+func (w *latencyWindow) percentile(p float64, minSamples int) (time.Duration, bool) {
+	w.mu.Lock()
+	samples := slices.Clone(w.samples)
+	w.mu.Unlock()
+
+	if len(samples) < max(minSamples, 1) {
+		return 0, false
+	}
+
+	slices.Sort(samples)
+
+	index := int(math.Ceil(p/100*float64(len(samples)))) - 1
+
+	return samples[max(index, 0)], true
+}
+
+// hedgingClient sends a duplicate of a query which has not returned within the hedge delay and returns the
+// first successful result, cancelling the other query.
+type hedgingClient struct {
+	client    NRDBClient
+	options   HedgeOptions
+	latencies sync.Map
+	metrics   providerMetrics
+}
+
+func newHedgingClient(client NRDBClient, options HedgeOptions, metrics providerMetrics) *hedgingClient {
+	return &hedgingClient{
+		client:  client,
+		options: options,
+		metrics: metrics,
+	}
+}
+
+type hedgeAttempt struct {
+	result *nrdb.NRDBResultContainer
+	err    error
+	hedge  bool
+}
+
+func (c *hedgingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	name := metricFromContext(ctx)
+	latencies := c.latencyWindow(name)
+
+	// Cancels the query which lost the race.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan hedgeAttempt, 2)
+
+	attempt := func(hedge bool) {
+		start := time.Now()
+
+		result, err := c.client.QueryWithContext(ctx, accountID, query)
+		if err == nil {
+			latencies.record(time.Since(start))
+		}
+
+		attempts <- hedgeAttempt{result: result, err: err, hedge: hedge}
+	}
+
+	go attempt(false)
+
+	pending := 1
+
+	var hedgeC <-chan time.Time
+
+	if delay, ok := c.delay(latencies); ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		hedgeC = timer.C
+	}
+
+	for {
+		select {
+		case <-hedgeC:
+			klog.V(debug).InfoS("Query has not returned yet, sending hedged query", logkeys.Metric, name)
+
+			c.metrics.hedgedQueriesTotal.WithLabelValues(name).Inc()
+
+			pending++
+
+			go attempt(true)
+		case a := <-attempts:
+			pending--
+
+			if a.err == nil {
+				if a.hedge {
+					c.metrics.hedgeWinsTotal.WithLabelValues(name).Inc()
+				}
+
+				return a.result, nil
+			}
+
+			// Wait for the other query, which may still succeed.
+			if pending > 0 {
+				continue
+			}
+
+			return nil, a.err
+		}
+	}
+}
+
+// delay returns the time after which the hedged query should be sent, if any.
+func (c *hedgingClient) delay(latencies *latencyWindow) (time.Duration, bool) {
+	if c.options.LatencyPercentile > 0 {
+		if delay, ok := latencies.percentile(c.options.LatencyPercentile, hedgeMinLatencySamples); ok {
+			return delay, true
+		}
+	}
+
+	return c.options.Delay, c.options.Delay > 0
+}
+
+func (c *hedgingClient) latencyWindow(name string) *latencyWindow {
+	latencies, _ := c.latencies.LoadOrStore(name, &latencyWindow{})
+
+	return latencies.(*latencyWindow) //nolint:forcetypeassert // Only latency windows are stored.
+}
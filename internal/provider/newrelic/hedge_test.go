@@ -0,0 +1,149 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_with_hedging(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("returns_result_of_hedged_query_and_cancels_original_query_when_it_is_slow", func(t *testing.T) {
+		t.Parallel()
+
+		client := &slowClient{slowCalls: map[int]bool{1: true}, cancelled: make(chan struct{}, 1)}
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.NRDBClient = client
+		options.Hedge = newrelic.HedgeOptions{Delay: 10 * time.Millisecond}
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		select {
+		case <-client.cancelled:
+		case <-ctx.Done():
+			t.Fatalf("Expected original query to be cancelled")
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_hedge_wins_total [ALPHA] Total number of hedged queries to the NewRelic backend which returned before the original query.
+		# TYPE newrelic_adapter_external_provider_hedge_wins_total counter
+		newrelic_adapter_external_provider_hedge_wins_total{metric="test_metric"} 1
+		# HELP newrelic_adapter_external_provider_hedged_queries_total [ALPHA] Total number of hedged queries sent to the NewRelic backend as the original query has not returned in time.
+		# TYPE newrelic_adapter_external_provider_hedged_queries_total counter
+		newrelic_adapter_external_provider_hedged_queries_total{metric="test_metric"} 1
+		`
+
+		metricNames := []string{
+			"newrelic_adapter_external_provider_hedged_queries_total",
+			"newrelic_adapter_external_provider_hedge_wins_total",
+		}
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricNames...); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("does_not_hedge_queries_returning_within_delay", func(t *testing.T) {
+		t.Parallel()
+
+		client := &slowClient{}
+
+		options, _ := testProviderOptions()
+		options.NRDBClient = client
+		options.Hedge = newrelic.HedgeOptions{Delay: time.Hour}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if calls := client.calls(); calls != 1 {
+			t.Fatalf("Expected 1 query, got %d", calls)
+		}
+	})
+
+	t.Run("hedges_queries_slower_than_observed_latency_percentile", func(t *testing.T) {
+		t.Parallel()
+
+		client := &slowClient{slowCalls: map[int]bool{11: true}}
+
+		options, _ := testProviderOptions()
+		options.NRDBClient = client
+		options.Hedge = newrelic.HedgeOptions{LatencyPercentile: 90}
+
+		p := testProvider(t, options)
+
+		for i := 0; i < 11; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if calls := client.calls(); calls != 12 {
+			t.Fatalf("Expected 12 queries, got %d", calls)
+		}
+	})
+}
+
+// slowClient blocks configured calls until their context is done.
+type slowClient struct {
+	slowCalls map[int]bool
+	cancelled chan struct{}
+
+	mu       sync.Mutex
+	numCalls int
+}
+
+func (c *slowClient) QueryWithContext(ctx context.Context, _ int, _ nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	c.mu.Lock()
+	c.numCalls++
+	call := c.numCalls
+	c.mu.Unlock()
+
+	if c.slowCalls[call] {
+		<-ctx.Done()
+
+		if c.cancelled != nil {
+			c.cancelled <- struct{}{}
+		}
+
+		return nil, ctx.Err() //nolint:wrapcheck // We don't care about wrapping in tests.
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{{"value": float64(1)}},
+	}, nil
+}
+
+func (c *slowClient) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.numCalls
+}
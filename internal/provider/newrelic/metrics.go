@@ -6,6 +6,7 @@ package newrelic
 import (
 	"fmt"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/component-base/metrics"
 )
 
@@ -14,8 +15,49 @@ const (
 	subsystem = "external_provider"
 )
 
+//nolint:gochecknoglobals // Slices cannot be constants.
+var queryDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
 type providerMetrics struct {
-	queriesTotal *metrics.CounterVec
+	queriesTotal      *metrics.CounterVec
+	queryDuration     *metrics.HistogramVec
+	queryRetriesTotal *metrics.CounterVec
+	// Circuit breaker state: 0 closed, 1 half-open, 2 open.
+	circuitBreakerState    *metrics.GaugeVec
+	rateLimitRejectedTotal *metrics.CounterVec
+	rateLimitDelayedTotal  *metrics.CounterVec
+	inFlightQueries        *metrics.Gauge
+	queuedQueries          *metrics.Gauge
+	throttleInterval       *metrics.Gauge
+	throttledQueriesTotal  *metrics.CounterVec
+	hedgedQueriesTotal     *metrics.CounterVec
+	hedgeWinsTotal         *metrics.CounterVec
+	fallbacksTotal         *metrics.CounterVec
+	servedValue            *metrics.GaugeVec
+	sampleTimestamp        *metrics.GaugeVec
+	lastSuccessfulQuery    *metrics.GaugeVec
+	// Cost of queries accounted from the metadata of NRDB responses.
+	queryInspectedEventsTotal     *metrics.CounterVec
+	queryWallClockTime            *metrics.HistogramVec
+	queryMessagesTotal            *metrics.CounterVec
+	estimatedDailyQueries         *collectedGaugeVec
+	estimatedDailyInspectedEvents *collectedGaugeVec
+}
+
+// collectedGaugeVec is a gauge vector which values are set by the collect function right before they are
+// collected, so values expensive to keep up to date are computed only when scraped.
+type collectedGaugeVec struct {
+	*metrics.GaugeVec
+	collect func()
+}
+
+// Collect implements prometheus.Collector.
+func (v *collectedGaugeVec) Collect(ch chan<- prometheus.Metric) {
+	if v.collect != nil {
+		v.collect()
+	}
+
+	v.GaugeVec.Collect(ch)
 }
 
 func getMetrics() providerMetrics {
@@ -27,7 +69,169 @@ func getMetrics() providerMetrics {
 				Subsystem:      subsystem,
 				Name:           "queries_total",
 				StabilityLevel: metrics.ALPHA,
-			}, []string{"result"}),
+			}, []string{"metric", "source", "result"}),
+		queryDuration: metrics.NewHistogramVec(
+			&metrics.HistogramOpts{
+				Help:           "Duration in seconds of queries to the NewRelic backend by metric, source and result.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "query_duration_seconds",
+				Buckets:        queryDurationBuckets,
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric", "source", "result"}),
+		queryRetriesTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of retried queries to the NewRelic backend by metric and class of the error.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "query_retries_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric", "class"}),
+		circuitBreakerState: metrics.NewGaugeVec(
+			&metrics.GaugeOpts{
+				Help:           "State of the circuit breaker guarding queries to the NewRelic backend: 0 closed, 1 half-open, 2 open.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "circuit_breaker_state",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"scope", "metric", "source"}),
+		rateLimitRejectedTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of queries to the NewRelic backend rejected due to exceeded rate limit.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "rate_limit_rejected_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		rateLimitDelayedTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of queries to the NewRelic backend delayed due to exceeded rate limit.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "rate_limit_delayed_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		inFlightQueries: metrics.NewGauge(
+			&metrics.GaugeOpts{
+				Help:           "Number of queries to the NewRelic backend currently being executed.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "in_flight_queries",
+				StabilityLevel: metrics.ALPHA,
+			}),
+		queuedQueries: metrics.NewGauge(
+			&metrics.GaugeOpts{
+				Help:           "Number of queries to the NewRelic backend waiting for a free slot.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "queued_queries",
+				StabilityLevel: metrics.ALPHA,
+			}),
+		throttleInterval: metrics.NewGauge(
+			&metrics.GaugeOpts{
+				Help:           "Interval in seconds enforced between queries to the NewRelic backend due to rate limiting responses, 0 when not throttled.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "throttle_interval_seconds",
+				StabilityLevel: metrics.ALPHA,
+			}),
+		throttledQueriesTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of queries to the NewRelic backend delayed or rejected due to rate limiting responses.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "throttled_queries_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		hedgedQueriesTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of hedged queries sent to the NewRelic backend as the original query has not returned in time.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "hedged_queries_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		hedgeWinsTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of hedged queries to the NewRelic backend which returned before the original query.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "hedge_wins_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		fallbacksTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of values served from a fallback as the query to the NewRelic backend failed.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "fallbacks_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric", "source"}),
+		servedValue: metrics.NewGaugeVec(
+			&metrics.GaugeOpts{
+				Help:           "Latest value served for the external metric.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "value",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric", "selector"}),
+		sampleTimestamp: metrics.NewGaugeVec(
+			&metrics.GaugeOpts{
+				Help:           "Unix timestamp in seconds of the sample of the latest value served for the external metric, or of the time it was served if the sample has no timestamp.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "sample_timestamp_seconds",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric", "selector"}),
+		lastSuccessfulQuery: metrics.NewGaugeVec(
+			&metrics.GaugeOpts{
+				Help:           "Unix timestamp in seconds of the latest successful query of the external metric to the NewRelic backend.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "last_successful_query_timestamp_seconds",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric", "selector"}),
+		queryInspectedEventsTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of events inspected by NRDB for queries of the external metric, as reported by NRDB performance statistics.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "query_inspected_events_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		queryWallClockTime: metrics.NewHistogramVec(
+			&metrics.HistogramOpts{
+				Help:           "Wall-clock time in seconds NRDB spent executing queries of the external metric, as reported by NRDB performance statistics.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "query_wall_clock_seconds",
+				Buckets:        queryDurationBuckets,
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		queryMessagesTotal: metrics.NewCounterVec(
+			&metrics.CounterOpts{
+				Help:           "Total number of messages, usually warnings, returned by NRDB with results of queries of the external metric.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "query_messages_total",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"}),
+		estimatedDailyQueries: &collectedGaugeVec{GaugeVec: metrics.NewGaugeVec(
+			&metrics.GaugeOpts{
+				Help:           "Estimated number of queries of the external metric sent to the NewRelic backend per day, extrapolated from recent queries.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "estimated_daily_queries",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"})},
+		estimatedDailyInspectedEvents: &collectedGaugeVec{GaugeVec: metrics.NewGaugeVec(
+			&metrics.GaugeOpts{
+				Help:           "Estimated number of events inspected by NRDB per day for queries of the external metric, extrapolated from recent queries.",
+				Namespace:      namespace,
+				Subsystem:      subsystem,
+				Name:           "estimated_daily_inspected_events",
+				StabilityLevel: metrics.ALPHA,
+			}, []string{"metric"})},
 	}
 }
 
@@ -40,5 +244,85 @@ func registerMetrics(registerFunc func(metrics.Registerable) error, providerMetr
 		return fmt.Errorf("registering queries total metric: %w", err)
 	}
 
+	if err := registerFunc(providerMetrics.queryDuration); err != nil {
+		return fmt.Errorf("registering query duration metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.queryRetriesTotal); err != nil {
+		return fmt.Errorf("registering query retries total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.circuitBreakerState); err != nil {
+		return fmt.Errorf("registering circuit breaker state metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.rateLimitRejectedTotal); err != nil {
+		return fmt.Errorf("registering rate limit rejected total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.rateLimitDelayedTotal); err != nil {
+		return fmt.Errorf("registering rate limit delayed total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.inFlightQueries); err != nil {
+		return fmt.Errorf("registering in flight queries metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.queuedQueries); err != nil {
+		return fmt.Errorf("registering queued queries metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.throttleInterval); err != nil {
+		return fmt.Errorf("registering throttle interval metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.throttledQueriesTotal); err != nil {
+		return fmt.Errorf("registering throttled queries total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.hedgedQueriesTotal); err != nil {
+		return fmt.Errorf("registering hedged queries total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.hedgeWinsTotal); err != nil {
+		return fmt.Errorf("registering hedge wins total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.fallbacksTotal); err != nil {
+		return fmt.Errorf("registering fallbacks total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.servedValue); err != nil {
+		return fmt.Errorf("registering served value metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.sampleTimestamp); err != nil {
+		return fmt.Errorf("registering sample timestamp metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.lastSuccessfulQuery); err != nil {
+		return fmt.Errorf("registering last successful query metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.queryInspectedEventsTotal); err != nil {
+		return fmt.Errorf("registering query inspected events total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.queryWallClockTime); err != nil {
+		return fmt.Errorf("registering query wall clock time metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.queryMessagesTotal); err != nil {
+		return fmt.Errorf("registering query messages total metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.estimatedDailyQueries); err != nil {
+		return fmt.Errorf("registering estimated daily queries metric: %w", err)
+	}
+
+	if err := registerFunc(providerMetrics.estimatedDailyInspectedEvents); err != nil {
+		return fmt.Errorf("registering estimated daily inspected events metric: %w", err)
+	}
+
 	return nil
 }
@@ -7,11 +7,14 @@ package newrelic
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 	"time"
 
 	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation/path"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,6 +23,10 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/apistatus"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/tracing"
 )
 
 const (
@@ -39,6 +46,17 @@ type directProvider struct {
 	accountID        int64
 	clusterName      string
 	metrics          providerMetrics
+	values           *valueRecorder
+	health           *healthRecorder
+	status           *statusRecorder
+	costs            *costRecorder
+	// breakers is nil when circuit breakers are disabled.
+	breakers *breakingClient
+	tracer   trace.Tracer
+	// redactTraces omits selectors and error messages from spans of requests.
+	redactTraces bool
+	// lastKnownGood holds the last value fetched by the query of each metric with last known good fallback.
+	lastKnownGood *knownValues
 }
 
 // ProviderOptions holds the configOptions of the provider.
@@ -47,7 +65,35 @@ type ProviderOptions struct {
 	NRDBClient      NRDBClient
 	AccountID       int64
 	ClusterName     string
-	RegisterFunc    func(metrics.Registerable) error
+	// Retry configures retrying queries failing with transient errors.
+	Retry RetryOptions
+	// CircuitBreaker configures failing queries fast while New Relic or a single query keeps failing.
+	CircuitBreaker CircuitBreakerOptions
+	// RateLimit limits the rate of all queries. Each metric may additionally configure its own limit.
+	RateLimit RateLimit
+	// WaitForRateLimit makes queries over the rate limit wait for the budget within the request deadline,
+	// instead of being rejected right away.
+	WaitForRateLimit bool
+	// Hedge configures sending a duplicate of queries which have not returned in time.
+	Hedge HedgeOptions
+	// MaxConcurrentQueries bounds the number of queries executed at the same time. Zero means no limit.
+	MaxConcurrentQueries int
+	// MaxQueryTimeout is the maximum timeout a metric may configure. Zero means no maximum.
+	MaxQueryTimeout time.Duration
+	// Throttle adapts the rate of queries to rate limiting responses of NerdGraph. It must be fed by the
+	// transport of the NRDB client.
+	Throttle *Throttle
+	// ValueMetrics configures exporting the latest served values of metrics.
+	ValueMetrics ValueMetricsOptions
+	// Cost configures accounting the cost of queries. Performance statistics of queries are accounted only
+	// when the NRDB client returns the raw response of NRDB, see WithPerformanceStats.
+	Cost CostOptions
+	// TracerProvider provides the tracer recording spans of requests and queries. If nil, no spans are recorded.
+	TracerProvider trace.TracerProvider
+	// RedactTracedQueries replaces literals of queries recorded in spans with placeholders and omits selectors
+	// and error messages, which may hold the query, from spans.
+	RedactTracedQueries bool
+	RegisterFunc        func(metrics.Registerable) error
 }
 
 // NewDirectProvider is the constructor for the direct provider.
@@ -60,36 +106,136 @@ func NewDirectProvider(options ProviderOptions) (provider.ExternalMetricsProvide
 		return nil, fmt.Errorf("a NRDBClient cannot be nil")
 	}
 
-	if err := validateExternalMetrics(options.ExternalMetrics); err != nil {
+	if err := options.Hedge.validate(); err != nil {
+		return nil, fmt.Errorf("validating hedge options: %w", err)
+	}
+
+	if err := validateExternalMetrics(options.ExternalMetrics, options.MaxQueryTimeout); err != nil {
 		return nil, fmt.Errorf("validating external metrics: %w", err)
 	}
 
 	for name := range options.ExternalMetrics {
-		klog.Infof("Registering metric %q", name)
+		klog.InfoS("Registering metric", logkeys.Metric, name)
 	}
 
-	klog.Infof("All queries will be executed for account ID %d", options.AccountID)
+	klog.InfoS("All queries will be executed for account", logkeys.AccountID, options.AccountID)
 
 	providerMetrics := getMetrics()
+	tracer := tracing.Tracer(options.TracerProvider, tracerName)
 
 	if err := registerMetrics(options.RegisterFunc, providerMetrics); err != nil {
 		return nil, fmt.Errorf("registering metrics: %w", err)
 	}
 
+	costs := newCostRecorder(options.Cost, providerMetrics, time.Now())
+	nrdbClient, breakers := decorateClient(options, providerMetrics, tracer, costs)
+
 	return &directProvider{
 		metricsSupported: options.ExternalMetrics,
-		nrdbClient:       options.NRDBClient,
+		nrdbClient:       nrdbClient,
 		accountID:        options.AccountID,
 		clusterName:      options.ClusterName,
 		metrics:          providerMetrics,
+		values:           newValueRecorder(options.ValueMetrics, providerMetrics),
+		health:           newHealthRecorder(),
+		status:           newStatusRecorder(),
+		costs:            costs,
+		breakers:         breakers,
+		tracer:           tracer,
+		redactTraces:     options.RedactTracedQueries,
+		lastKnownGood:    newKnownValues(),
 	}, nil
 }
 
-func validateExternalMetrics(externalMetrics map[string]Metric) error {
-	for name := range externalMetrics {
+// decorateClient wraps the configured NRDB client with enabled resiliency features. Each decorator wraps
+// the previous one, so the decorator added last is the first to handle the query. Circuit breakers are returned
+// as well, so their state can be reported by health checks.
+func decorateClient(
+	options ProviderOptions, providerMetrics providerMetrics, tracer trace.Tracer, costs *costRecorder,
+) (NRDBClient, *breakingClient) {
+	// Accounting is placed right above the client, so the cost of every query sent is accounted, including
+	// retried and hedged ones.
+	nrdbClient := NRDBClient(newAccountingClient(options.NRDBClient, costs, providerMetrics))
+
+	// Tracing is placed right above accounting, so a span is recorded for every query sent as well.
+	nrdbClient = newTracingClient(nrdbClient, tracer, options.RedactTracedQueries)
+
+	var breakers *breakingClient
+
+	// Concurrency limit is placed right above the client, so slots are held only by queries being executed.
+	if options.MaxConcurrentQueries > 0 {
+		klog.InfoS("Limiting number of queries executed at the same time",
+			logkeys.MaxConcurrentQueries, options.MaxConcurrentQueries)
+
+		nrdbClient = newConcurrencyLimitingClient(nrdbClient, options.MaxConcurrentQueries, providerMetrics)
+	}
+
+	// Circuit breakers are placed below retries, so every attempt is accounted and retries stop once they open.
+	if options.CircuitBreaker.FailureThreshold > 0 {
+		klog.InfoS("Circuit breakers will open after consecutive failures",
+			logkeys.FailureThreshold, options.CircuitBreaker.FailureThreshold)
+
+		breakers = newBreakingClient(nrdbClient, options.CircuitBreaker, providerMetrics)
+		nrdbClient = breakers
+	}
+
+	// Throttling is placed above circuit breakers, so queries delayed or rejected by it neither hold
+	// concurrency slots nor affect the breaker state.
+	if options.Throttle != nil {
+		nrdbClient = newThrottlingClient(nrdbClient, options.Throttle, providerMetrics)
+	}
+
+	// Rate limits are placed above circuit breakers, so rejected queries do not affect the breaker state,
+	// and below retries, so retried queries consume the budget as well.
+	if rateLimitingEnabled(options.RateLimit, options.ExternalMetrics) {
+		nrdbClient = newLimitingClient(
+			nrdbClient, options.RateLimit, options.ExternalMetrics, options.WaitForRateLimit, providerMetrics,
+		)
+	}
+
+	// Hedging is placed below retries, so each retry can be hedged, and above other decorators, so both
+	// queries are subject to limits and only the query which lost the race is cancelled.
+	if options.Hedge.enabled() {
+		klog.InfoS("Queries which have not returned in time will be hedged")
+
+		nrdbClient = newHedgingClient(nrdbClient, options.Hedge, providerMetrics)
+	}
+
+	if options.Retry.MaxRetries > 0 {
+		klog.InfoS("Queries failing with transient errors will be retried", logkeys.MaxRetries, options.Retry.MaxRetries,
+			logkeys.MaxRequests, options.Retry.MaxRequests())
+
+		nrdbClient = newRetryingClient(nrdbClient, options.Retry, providerMetrics)
+	}
+
+	return nrdbClient, breakers
+}
+
+// metricNotConfiguredError returns error with NotFound API status for the metric which is not configured.
+func metricNotConfiguredError(name string) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotFound,
+		Reason:  metav1.StatusReasonNotFound,
+		Message: fmt.Sprintf("metric %q not configured", name),
+	}}
+}
+
+func validateExternalMetrics(externalMetrics map[string]Metric, maxQueryTimeout time.Duration) error {
+	for name, metric := range externalMetrics {
 		if err := isValidExternalMetricName(name); err != nil {
 			return fmt.Errorf("invalid metric name %q: %w", name, err)
 		}
+
+		if err := metric.validateTimeout(maxQueryTimeout); err != nil {
+			return fmt.Errorf("invalid timeout of metric %q: %w", name, err)
+		}
+
+		for i, fallback := range metric.Fallbacks {
+			if err := fallback.validate(); err != nil {
+				return fmt.Errorf("invalid fallback %d of metric %q: %w", i, name, err)
+			}
+		}
 	}
 
 	return nil
@@ -112,6 +258,42 @@ type Metric struct {
 	Query               Query `json:"query"`
 	RemoveClusterFilter bool  `json:"removeClusterFilter"`
 	OldestSampleAllowed int64 `json:"oldestSampleAllowed"`
+	// RateLimit limits the rate of queries of this metric, in addition to the global limit.
+	RateLimit RateLimit `json:"rateLimit"`
+	// TimeoutSeconds bounds the time spent on a single request of this metric, including retries.
+	// Zero means the request is bounded only by the client timeout and the request deadline.
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+	// Fallbacks are tried in order when the query fails.
+	Fallbacks []Fallback `json:"fallbacks"`
+
+	// source is the source of the value the query fetches, so fallback queries are accounted separately from
+	// the query of the metric. Defaults to sourceQuery.
+	source string
+}
+
+// querySource returns the source of the value the query of the metric fetches.
+func (m Metric) querySource() string {
+	if m.source == "" {
+		return sourceQuery
+	}
+
+	return m.source
+}
+
+func (m Metric) timeout() time.Duration {
+	return time.Duration(m.TimeoutSeconds) * time.Second
+}
+
+func (m Metric) validateTimeout(maxQueryTimeout time.Duration) error {
+	if m.TimeoutSeconds < 0 {
+		return fmt.Errorf("may not be negative, got %d", m.TimeoutSeconds)
+	}
+
+	if maxQueryTimeout > 0 && m.timeout() > maxQueryTimeout {
+		return fmt.Errorf("%v exceeds maximum of %v", m.timeout(), maxQueryTimeout)
+	}
+
+	return nil
 }
 
 // NRDBClient is the interface a client should respect to be used in the provider to retrieve metrics.
@@ -119,13 +301,66 @@ type NRDBClient interface {
 	QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error)
 }
 
+type (
+	metricContextKey      struct{}
+	querySourceContextKey struct{}
+)
+
+// contextWithMetric returns context carrying the name of the requested metric, so NRDB client decorators
+// can account queries per metric.
+func contextWithMetric(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, metricContextKey{}, name)
+}
+
+// metricFromContext returns the name of the requested metric carried by the given context, if any.
+func metricFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(metricContextKey{}).(string)
+
+	return name
+}
+
+// contextWithQuerySource returns context carrying the source of the value fetched by the query, so NRDB
+// client decorators can tell fallback queries from the query of the metric they share limits with.
+func contextWithQuerySource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, querySourceContextKey{}, source)
+}
+
+// querySourceFromContext returns the source of the value fetched by the query carried by the given context.
+func querySourceFromContext(ctx context.Context) string {
+	if source, ok := ctx.Value(querySourceContextKey{}).(string); ok {
+		return source
+	}
+
+	return sourceQuery
+}
+
 // GetExternalMetric returns the requested metric.
-func (p *directProvider) GetExternalMetric(ctx context.Context, _ string, match labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
-	value, timestamp, err := p.getMetric(ctx, info.Metric, match)
+func (p *directProvider) GetExternalMetric(ctx context.Context, namespace string, match labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) { //nolint:lll // External interface requirement.
+	ctx, span := p.tracer.Start(ctx, "DirectProvider.GetExternalMetric",
+		tracing.RequestAttributes(info.Metric, match, p.redactTraces))
+
+	result, err := p.getExternalMetric(ctx, namespace, match, info, span)
+
+	tracing.End(span, err, p.redactTraces)
+
+	return result, err
+}
+
+func (p *directProvider) getExternalMetric(
+	ctx context.Context, namespace string, match labels.Selector, info provider.ExternalMetricInfo, span trace.Span,
+) (*external_metrics.ExternalMetricValueList, error) {
+	value, timestamp, source, err := p.getMetric(ctx, namespace, info.Metric, match)
+	if source != "" {
+		span.SetAttributes(sourceKey.String(source))
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("getting metric value: %w", err)
+		return nil, apistatus.Preserve(fmt.Errorf("getting metric value: %w", err))
 	}
 
+	p.values.recordValue(info.Metric, match, value, timestamp, time.Now())
+	p.status.recordValue(info.Metric, value, timestamp)
+
 	valueToBeParsed := fmt.Sprintf("%f", value)
 
 	quantity, err := resource.ParseQuantity(valueToBeParsed)
@@ -143,7 +378,7 @@ func (p *directProvider) GetExternalMetric(ctx context.Context, _ string, match
 		Items: []external_metrics.ExternalMetricValue{
 			{
 				MetricName:   info.Metric,
-				MetricLabels: map[string]string{},
+				MetricLabels: sourceLabels(source),
 				Timestamp:    t,
 				Value:        quantity,
 			},
@@ -164,40 +399,121 @@ func (p *directProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo
 	return em
 }
 
-// GetMetric fetches a value of a metric calling QueryWithContext of NRDBClient.
-func (p *directProvider) getMetric(ctx context.Context, name string, sl labels.Selector) (float64, *time.Time, error) {
+// Supports returns true if the given metric is configured, so callers do not have to list all metrics
+// to find out.
+func (p *directProvider) Supports(metric string) bool {
+	_, ok := p.metricsSupported[metric]
+
+	return ok
+}
+
+// GetMetric fetches a value of a metric requested from the given namespace calling QueryWithContext of NRDBClient.
+// When the query fails, configured fallbacks of the metric are tried. Source of the returned value is returned
+// as well.
+func (p *directProvider) getMetric(
+	ctx context.Context, namespace, name string, sl labels.Selector,
+) (float64, *time.Time, string, error) {
 	if err := isValidExternalMetricName(name); err != nil {
-		return 0, nil, fmt.Errorf("invalid metric name %q: %w", name, err)
+		return 0, nil, "", apierrors.NewBadRequest(fmt.Sprintf("invalid metric name %q: %v", name, err))
 	}
 
 	metric, ok := p.metricsSupported[name]
 	if !ok {
-		return 0, nil, fmt.Errorf("metric %q not configured", name)
+		return 0, nil, "", metricNotConfiguredError(name)
 	}
 
+	value, timestamp, err := p.executeQuery(ctx, namespace, name, metric, p.accountID, sl)
+	if err != nil {
+		p.health.recordFailure(name, err, time.Now())
+
+		return p.fallback(ctx, namespace, name, metric, sl, err)
+	}
+
+	now := time.Now()
+
+	p.health.recordSuccess(name, now)
+
+	if maxAge := metric.lastKnownGoodMaxAge(); maxAge > 0 {
+		p.lastKnownGood.store(lastKnownGoodKey(name, sl), knownValue{
+			value:     value,
+			timestamp: timestamp,
+			fetchedAt: now,
+			expiresAt: now.Add(maxAge),
+		}, now)
+	}
+
+	p.values.recordQuery(name, sl, now)
+
+	return value, timestamp, sourceQuery, nil
+}
+
+// executeQuery fetches a value of the given metric query from the given account. Namespace of the request is
+// logged only.
+func (p *directProvider) executeQuery(
+	ctx context.Context, namespace, name string, metric Metric, accountID int64, sl labels.Selector,
+) (float64, *time.Time, error) {
 	q := metric.Query
 
 	query, err := q.addClusterFilter(p.clusterName, metric.RemoveClusterFilter).addMatchFilter(sl)
 	if err != nil {
-		return 0, nil, fmt.Errorf("building query: %w", err)
+		return 0, nil, apierrors.NewBadRequest(fmt.Sprintf("building query: %v", err))
 	}
 
-	klog.V(debug).Infof("Executing %q", query)
+	klog.V(debug).InfoS("Executing query", logkeys.Metric, name, logkeys.Namespace, namespace,
+		logkeys.Selector, selectorString(sl), logkeys.AccountID, accountID, logkeys.Query, query)
 
 	// Define inline so it can be used only from a single place in code for consistency,
 	// to avoid possibly adding query to error message twice.
 	errWithQuery := func(format string, a ...interface{}) error {
-		return fmt.Errorf("query %q: %w", query, fmt.Errorf(format, a...))
+		return &failedQueryError{query: query, err: fmt.Errorf(format, a...)}
+	}
+
+	if timeout := metric.timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	source := metric.querySource()
+
+	// Status of the metric reports its own query only, so a successful fallback does not hide its failure.
+	if source == sourceQuery {
+		p.status.recordQuery(name, query, start)
 	}
 
-	queryResult, err := p.nrdbClient.QueryWithContext(ctx, int(p.accountID), nrdb.NRQL(query))
+	queryResult, err := p.nrdbClient.QueryWithContext(
+		contextWithQuerySource(contextWithMetric(ctx, name), source), int(accountID), nrdb.NRQL(query),
+	)
 	if err != nil {
-		p.metrics.queriesTotal.WithLabelValues("err").Inc()
+		class := classifyError(err)
+
+		// Failed queries are counted by the class of the error, as e.g. timeouts usually call for tuning
+		// the query or its timeout, while authentication errors call for fixing the API key.
+		p.observeQuery(name, source, class, start)
+
+		klog.V(debug).ErrorS(err, "Query failed", logkeys.Metric, name, logkeys.Source, source,
+			logkeys.Namespace, namespace, logkeys.Selector, selectorString(sl), logkeys.AccountID, accountID,
+			logkeys.Duration, time.Since(start), logkeys.ErrorClass, class)
+
+		// Errors of unknown class and rejections carrying their own API status are returned as they are.
+		if class != errorClassUnknown && class != errorClassRejected {
+			err = &queryError{err: err, class: class}
+		}
 
 		return 0, nil, errWithQuery("executing query: %w", err)
 	}
 
-	p.metrics.queriesTotal.WithLabelValues("ok").Inc()
+	p.observeQuery(name, source, "ok", start)
+
+	if source == sourceQuery {
+		p.status.recordLatency(name, time.Since(start))
+	}
+
+	klog.V(debug).InfoS("Query succeeded", logkeys.Metric, name, logkeys.Source, source,
+		logkeys.Namespace, namespace, logkeys.Selector, selectorString(sl), logkeys.AccountID, accountID,
+		logkeys.Duration, time.Since(start))
 
 	if err := p.validateQueryResult(queryResult); err != nil {
 		return 0, nil, errWithQuery("validating result: %w", err)
@@ -205,7 +521,7 @@ func (p *directProvider) getMetric(ctx context.Context, name string, sl labels.S
 
 	timestamp, err := timestampFromResult(queryResult.Results[0], metric.OldestSampleAllowed, query)
 	if err != nil {
-		return 0, nil, fmt.Errorf("getting timestamp: %w", err)
+		return 0, nil, errWithQuery("getting timestamp: %w", err)
 	}
 
 	f, err := p.extractReturnValue(queryResult)
@@ -216,6 +532,22 @@ func (p *directProvider) getMetric(ctx context.Context, name string, sl labels.S
 	return f, timestamp, nil
 }
 
+// selectorString returns the given selector formatted for logs.
+func selectorString(sl labels.Selector) string {
+	if sl == nil {
+		return ""
+	}
+
+	return sl.String()
+}
+
+// observeQuery records the result and the duration of a query of the given metric fetching the value from
+// the given source. Only configured metrics and fallbacks are queried, so the labels are bounded.
+func (p *directProvider) observeQuery(name, source, result string, start time.Time) {
+	p.metrics.queriesTotal.WithLabelValues(name, source, result).Inc()
+	p.metrics.queryDuration.WithLabelValues(name, source, result).Observe(time.Since(start).Seconds())
+}
+
 func (p *directProvider) validateQueryResult(answer *nrdb.NRDBResultContainer) error {
 	if answer == nil {
 		return fmt.Errorf("no error present, but the answer is nil")
@@ -243,15 +575,15 @@ func (p *directProvider) validateQueryResult(answer *nrdb.NRDBResultContainer) e
 func timestampFromResult(nrdbResult nrdb.NRDBResult, oldestSampleAllowed int64, query Query) (*time.Time, error) {
 	timestampRaw, ok := nrdbResult["timestamp"]
 	if !ok {
-		klog.V(debug).Infof("The query %q returns samples without the timestamp "+
-			"useful to validate the sample", query)
+		klog.V(debug).InfoS("Query returns samples without the timestamp useful to validate the sample",
+			logkeys.Query, query)
 
 		return nil, nil
 	}
 
 	timestampFloat, ok := timestampRaw.(float64)
 	if !ok {
-		klog.V(debug).Infof("The query %q returns samples with a 'no float64' timestamp", query)
+		klog.V(debug).InfoS("Query returns samples with a 'no float64' timestamp", logkeys.Query, query)
 
 		return nil, nil
 	}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -15,8 +16,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/component-base/metrics"
 	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
 
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
@@ -27,6 +30,7 @@ const (
 	testClusterName = "testCluster"
 	testMetricName  = "test_metric"
 	testQuery       = "select test from testSample limit 1"
+	testAccountID   = 1
 )
 
 //nolint:funlen,gocognit,cyclop // Just a large test suite.
@@ -105,7 +109,7 @@ func Test_Getting_external_metric(t *testing.T) {
 			expectedMetric := bytes.NewBufferString(`
 # HELP newrelic_adapter_external_provider_queries_total [ALPHA] Total number of queries to the NewRelic backend.
 # TYPE newrelic_adapter_external_provider_queries_total counter
-newrelic_adapter_external_provider_queries_total{result="ok"} 1
+newrelic_adapter_external_provider_queries_total{metric="test_metric",result="ok",source="query"} 1
 `)
 			if err := metricsTestutil.GatherAndCompare(
 				registry,
@@ -115,6 +119,23 @@ newrelic_adapter_external_provider_queries_total{result="ok"} 1
 				t.Fatalf("Unexpected error while gathering cache metrics: %v", err)
 			}
 		})
+
+		t.Run("observes_query_duration_metric", func(t *testing.T) {
+			t.Parallel()
+
+			histogram, err := metricsTestutil.GetHistogramVecFromGatherer(
+				registry,
+				"newrelic_adapter_external_provider_query_duration_seconds",
+				map[string]string{"metric": testMetricName, "result": "ok"},
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error while gathering query duration metric: %v", err)
+			}
+
+			if count := histogram.GetAggregatedSampleCount(); count != 1 {
+				t.Fatalf("Expected 1 observed query, got %d", count)
+			}
+		})
 	})
 
 	t.Run("filters_metrics_by_configured_cluster_name_when_remove_cluster_filter_is_false", func(t *testing.T) {
@@ -369,7 +390,41 @@ newrelic_adapter_external_provider_queries_total{result="ok"} 1
 		expectedMetric := bytes.NewBufferString(`
 # HELP newrelic_adapter_external_provider_queries_total [ALPHA] Total number of queries to the NewRelic backend.
 # TYPE newrelic_adapter_external_provider_queries_total counter
-newrelic_adapter_external_provider_queries_total{result="err"} 1
+newrelic_adapter_external_provider_queries_total{metric="test_metric",result="unknown",source="query"} 1
+`)
+
+		if err := metricsTestutil.GatherAndCompare(
+			registry,
+			expectedMetric,
+			"newrelic_adapter_external_provider_queries_total",
+		); err != nil {
+			t.Fatalf("Unexpected error while gathering cache metrics: %v", err)
+		}
+	})
+
+	t.Run("increments_timed_out_queries_metric_when_query_exceeds_metric_timeout", func(t *testing.T) {
+		t.Parallel()
+
+		client := &blockingClient{release: make(chan struct{})}
+		defer close(client.release)
+
+		providerOptions, _ := testProviderOptions()
+		providerOptions.NRDBClient = client
+		providerOptions.ExternalMetrics[testMetricName] = newrelic.Metric{Query: testQuery, TimeoutSeconds: 1}
+
+		registry := metrics.NewKubeRegistry()
+		providerOptions.RegisterFunc = registry.Register
+
+		p := testProvider(t, providerOptions)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Errorf("Expected error getting external metric")
+		}
+
+		expectedMetric := bytes.NewBufferString(`
+# HELP newrelic_adapter_external_provider_queries_total [ALPHA] Total number of queries to the NewRelic backend.
+# TYPE newrelic_adapter_external_provider_queries_total counter
+newrelic_adapter_external_provider_queries_total{metric="test_metric",result="timeout",source="query"} 1
 `)
 
 		if err := metricsTestutil.GatherAndCompare(
@@ -389,7 +444,7 @@ newrelic_adapter_external_provider_queries_total{result="err"} 1
 			providerOptions newrelic.ProviderOptions,
 			selector labels.Selector,
 			info provider.ExternalMetricInfo,
-		) {
+		) error {
 			t.Helper()
 
 			p := testProvider(t, providerOptions)
@@ -402,6 +457,16 @@ newrelic_adapter_external_provider_queries_total{result="err"} 1
 			if r != nil {
 				t.Errorf("Expected result to be nil, got %v", r)
 			}
+
+			return err
+		}
+
+		expectStatusCode := func(t *testing.T, err error, expectedCode int32) {
+			t.Helper()
+
+			if code := responsewriters.ErrorToAPIStatus(err).Code; code != expectedCode {
+				t.Errorf("Expected API status code %d, got %d: %v", expectedCode, code, err)
+			}
 		}
 
 		// This subtest checks that we fail given any client error.
@@ -481,7 +546,8 @@ newrelic_adapter_external_provider_queries_total{result="err"} 1
 
 			providerOptions, _ := testProviderOptions()
 
-			expectGetFails(t, providerOptions, nil, provider.ExternalMetricInfo{Metric: "not_existing_metric"})
+			err := expectGetFails(t, providerOptions, nil, provider.ExternalMetricInfo{Metric: "not_existing_metric"})
+			expectStatusCode(t, err, http.StatusNotFound)
 		})
 
 		t.Run("metric_request_use_unsupported_operator_in_selector", func(t *testing.T) {
@@ -496,7 +562,8 @@ newrelic_adapter_external_provider_queries_total{result="err"} 1
 				t.Fatalf("Unexpected error building requirement: %v", err)
 			}
 
-			expectGetFails(t, providerOptions, s.Add(*r1), provider.ExternalMetricInfo{Metric: testMetricName})
+			err = expectGetFails(t, providerOptions, s.Add(*r1), provider.ExternalMetricInfo{Metric: testMetricName})
+			expectStatusCode(t, err, http.StatusBadRequest)
 		})
 
 		t.Run("requested_metric_has_uppercase_characters_in_name", func(t *testing.T) {
@@ -504,7 +571,8 @@ newrelic_adapter_external_provider_queries_total{result="err"} 1
 
 			providerOptions, _ := testProviderOptions()
 
-			expectGetFails(t, providerOptions, nil, provider.ExternalMetricInfo{Metric: "Test"})
+			err := expectGetFails(t, providerOptions, nil, provider.ExternalMetricInfo{Metric: "Test"})
+			expectStatusCode(t, err, http.StatusBadRequest)
 		})
 	})
 }
@@ -535,6 +603,25 @@ func Test_Listing_available_metrics_returns_all_configured_metrics(t *testing.T)
 	}
 }
 
+func Test_Provider_supports_only_configured_metrics(t *testing.T) {
+	t.Parallel()
+
+	options, _ := testProviderOptions()
+
+	p, ok := testProvider(t, options).(interface{ Supports(metric string) bool })
+	if !ok {
+		t.Fatalf("Expected provider to tell whether it supports a metric")
+	}
+
+	if !p.Supports(testMetricName) {
+		t.Errorf("Expected metric %q to be supported", testMetricName)
+	}
+
+	if p.Supports("not_configured") {
+		t.Errorf("Expected metric %q not to be supported", "not_configured")
+	}
+}
+
 func Test_Creating_provider_returns_error_when(t *testing.T) {
 	t.Parallel()
 
@@ -547,6 +634,25 @@ func Test_Creating_provider_returns_error_when(t *testing.T) {
 		"any_of_configured_external_metrics_has_uppercase_character_in_name": func(o *newrelic.ProviderOptions) {
 			o.ExternalMetrics["Test"] = newrelic.Metric{}
 		},
+		"any_of_configured_external_metrics_has_fallback_with_no_source": func(o *newrelic.ProviderOptions) {
+			o.ExternalMetrics[testMetricName] = newrelic.Metric{Query: testQuery, Fallbacks: []newrelic.Fallback{{}}}
+		},
+		"any_of_configured_external_metrics_has_max_age_set_on_static_fallback": func(o *newrelic.ProviderOptions) {
+			o.ExternalMetrics[testMetricName] = newrelic.Metric{
+				Query:     testQuery,
+				Fallbacks: []newrelic.Fallback{{Value: ptr.To(1.0), MaxAgeSeconds: 60}},
+			}
+		},
+		"hedge_latency_percentile_is_out_of_range": func(o *newrelic.ProviderOptions) {
+			o.Hedge = newrelic.HedgeOptions{LatencyPercentile: 100}
+		},
+		"any_of_configured_external_metrics_has_negative_timeout": func(o *newrelic.ProviderOptions) {
+			o.ExternalMetrics[testMetricName] = newrelic.Metric{Query: testQuery, TimeoutSeconds: -1}
+		},
+		"any_of_configured_external_metrics_has_timeout_exceeding_maximum": func(o *newrelic.ProviderOptions) {
+			o.MaxQueryTimeout = time.Minute
+			o.ExternalMetrics[testMetricName] = newrelic.Metric{Query: testQuery, TimeoutSeconds: 61}
+		},
 	}
 
 	for testCaseName, mutateF := range cases {
@@ -608,7 +714,7 @@ func testProviderOptions() (newrelic.ProviderOptions, *testClient) {
 	return newrelic.ProviderOptions{
 		ExternalMetrics: map[string]newrelic.Metric{testMetricName: {Query: testQuery, RemoveClusterFilter: true}},
 		NRDBClient:      client,
-		AccountID:       1,
+		AccountID:       testAccountID,
 		ClusterName:     testClusterName,
 	}, client
 }
@@ -0,0 +1,157 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+// RateLimit holds the configuration of a token bucket limiting queries to NerdGraph.
+type RateLimit struct {
+	// QueriesPerMinute is the rate at which the bucket is refilled. Zero disables the limit.
+	QueriesPerMinute float64 `json:"queriesPerMinute"`
+	// Burst is the size of the bucket. Defaults to 1.
+	Burst int `json:"burst"`
+}
+
+func (r RateLimit) enabled() bool {
+	return r.QueriesPerMinute > 0
+}
+
+func (r RateLimit) limiter() *rate.Limiter {
+	burst := r.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(r.QueriesPerMinute/60), burst)
+}
+
+// limitingClient limits the rate of queries globally and per metric. Queries over the budget either wait
+// for the budget, as long as the request context deadline allows it, or are rejected with TooManyRequests
+// API status.
+type limitingClient struct {
+	client  NRDBClient
+	global  *rate.Limiter
+	metric  map[string]*rate.Limiter
+	wait    bool
+	metrics providerMetrics
+}
+
+func newLimitingClient(
+	client NRDBClient, global RateLimit, externalMetrics map[string]Metric, wait bool, metrics providerMetrics,
+) *limitingClient {
+	c := &limitingClient{
+		client:  client,
+		metric:  map[string]*rate.Limiter{},
+		wait:    wait,
+		metrics: metrics,
+	}
+
+	if global.enabled() {
+		c.global = global.limiter()
+	}
+
+	for name, metric := range externalMetrics {
+		if metric.RateLimit.enabled() {
+			c.metric[name] = metric.RateLimit.limiter()
+		}
+	}
+
+	return c
+}
+
+// rateLimitingEnabled returns true if any rate limit is configured.
+func rateLimitingEnabled(global RateLimit, externalMetrics map[string]Metric) bool {
+	if global.enabled() {
+		return true
+	}
+
+	for _, metric := range externalMetrics {
+		if metric.RateLimit.enabled() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *limitingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	name := metricFromContext(ctx)
+
+	if err := c.reserve(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return c.client.QueryWithContext(ctx, accountID, query) //nolint:wrapcheck // Decorator should not alter errors.
+}
+
+// reserve takes a token from the global and the metric buckets, waiting for them if configured.
+func (c *limitingClient) reserve(ctx context.Context, name string) error {
+	now := time.Now()
+	reservations := []*rate.Reservation{}
+
+	for _, limiter := range []*rate.Limiter{c.global, c.metric[name]} {
+		if limiter != nil {
+			reservations = append(reservations, limiter.ReserveN(now, 1))
+		}
+	}
+
+	var delay time.Duration
+
+	for _, r := range reservations {
+		if d := r.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+
+	if delay == 0 {
+		return nil
+	}
+
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+
+	if !c.wait || (hasDeadline && time.Until(deadline) < delay) {
+		cancel()
+
+		c.metrics.rateLimitRejectedTotal.WithLabelValues(name).Inc()
+
+		return apierrors.NewTooManyRequests(
+			fmt.Sprintf("query rate limit exceeded for metric %q, retry in %v", name, delay.Round(time.Millisecond)),
+			int(math.Ceil(delay.Seconds())),
+		)
+	}
+
+	c.metrics.rateLimitDelayedTotal.WithLabelValues(name).Inc()
+
+	klog.V(debug).InfoS("Delaying query due to rate limit", logkeys.Metric, name, logkeys.Delay, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		cancel()
+
+		return fmt.Errorf("waiting for rate limit: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
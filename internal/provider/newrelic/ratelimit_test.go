@@ -0,0 +1,148 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const otherMetricName = "other_metric"
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_with_rate_limit(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("rejects_queries_over_global_limit_with_too_many_requests_status", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.RegisterFunc = registry.Register
+		options.RateLimit = newrelic.RateLimit{QueriesPerMinute: 1}
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected too many requests error, got: %v", err)
+		}
+
+		if code := responsewriters.ErrorToAPIStatus(err).Code; code != http.StatusTooManyRequests {
+			t.Fatalf("Expected API status code %d, got %d", http.StatusTooManyRequests, code)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_rate_limit_rejected_total [ALPHA] Total number of queries to the NewRelic backend rejected due to exceeded rate limit.
+		# TYPE newrelic_adapter_external_provider_rate_limit_rejected_total counter
+		newrelic_adapter_external_provider_rate_limit_rejected_total{metric="test_metric"} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_rate_limit_rejected_total"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("applies_metric_limit_only_to_given_metric", func(t *testing.T) {
+		t.Parallel()
+
+		options, _ := testProviderOptions()
+		options.ExternalMetrics[testMetricName] = newrelic.Metric{
+			Query:     testQuery,
+			RateLimit: newrelic.RateLimit{QueriesPerMinute: 1},
+		}
+		options.ExternalMetrics[otherMetricName] = newrelic.Metric{Query: testQuery}
+
+		p := testProvider(t, options)
+
+		for i := 0; i < 3; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: otherMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected too many requests error, got: %v", err)
+		}
+	})
+
+	t.Run("delays_queries_over_limit_when_configured_to_wait", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.RegisterFunc = registry.Register
+		options.RateLimit = newrelic.RateLimit{QueriesPerMinute: 600}
+		options.WaitForRateLimit = true
+
+		p := testProvider(t, options)
+
+		for i := 0; i < 2; i++ {
+			if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_rate_limit_delayed_total [ALPHA] Total number of queries to the NewRelic backend delayed due to exceeded rate limit.
+		# TYPE newrelic_adapter_external_provider_rate_limit_delayed_total counter
+		newrelic_adapter_external_provider_rate_limit_delayed_total{metric="test_metric"} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_rate_limit_delayed_total"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("rejects_queries_which_would_wait_beyond_request_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		options, _ := testProviderOptions()
+		options.RateLimit = newrelic.RateLimit{QueriesPerMinute: 1}
+		options.WaitForRateLimit = true
+
+		p := testProvider(t, options)
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected too many requests error, got: %v", err)
+		}
+	})
+}
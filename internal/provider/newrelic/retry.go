@@ -0,0 +1,143 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultRetryInitialBackoff is the time to wait before the first retry when none is configured.
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+
+	// DefaultRetryMaxBackoff is the maximum time to wait between retries when none is configured.
+	DefaultRetryMaxBackoff = 10 * time.Second
+
+	// ClientAttempts is the number of HTTP requests the NerdGraph client itself makes for a single query
+	// failing with a transient error before returning it. The client does not allow disabling its retries,
+	// so each retry of this layer multiplies them.
+	ClientAttempts = 4
+)
+
+// RetryOptions holds the configuration of retrying failed queries.
+type RetryOptions struct {
+	// MaxRetries is the number of times a query failing with a transient error is retried.
+	// Zero disables retries. As the NerdGraph client retries on its own, a single query may result in up to
+	// (MaxRetries+1)*ClientAttempts HTTP requests, see MaxRequests.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// MaxRequests returns the maximum number of HTTP requests sent to NerdGraph for a single query, including
+// retries of the NerdGraph client.
+func (o RetryOptions) MaxRequests() int {
+	retries := o.MaxRetries
+	if retries < 0 {
+		retries = 0
+	}
+
+	return (retries + 1) * ClientAttempts
+}
+
+// retryingClient retries queries failing with transient errors with exponential backoff and jitter,
+// as long as the request context deadline allows it.
+type retryingClient struct {
+	client         NRDBClient
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	metrics        providerMetrics
+}
+
+func newRetryingClient(client NRDBClient, options RetryOptions, metrics providerMetrics) *retryingClient {
+	initialBackoff := options.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultRetryInitialBackoff
+	}
+
+	maxBackoff := options.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+
+	return &retryingClient{
+		client:         client,
+		maxRetries:     options.MaxRetries,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		metrics:        metrics,
+	}
+}
+
+func (c *retryingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	backoff := c.initialBackoff
+
+	for retry := 0; ; retry++ {
+		result, err := c.client.QueryWithContext(ctx, accountID, query)
+		if err == nil {
+			return result, nil
+		}
+
+		class := classifyError(err)
+
+		if retry >= c.maxRetries || !isTransient(class) || ctx.Err() != nil {
+			return nil, retriesExhausted(retry, err)
+		}
+
+		wait := jitter(backoff)
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, retriesExhausted(retry, err)
+		}
+
+		c.metrics.queryRetriesTotal.WithLabelValues(metricFromContext(ctx), class).Inc()
+
+		klog.V(debug).InfoS("Retrying query", logkeys.Query, query, logkeys.Delay, wait, logkeys.ErrorClass, class,
+			logkeys.Error, err)
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, retriesExhausted(retry, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+func retriesExhausted(retries int, err error) error {
+	if retries == 0 {
+		return err
+	}
+
+	return fmt.Errorf("giving up after %d retries: %w", retries, err)
+}
+
+// jitter returns random duration between half and full of the given backoff, so concurrent requests
+// failing at the same time do not retry at the same time.
+func jitter(backoff time.Duration) time.Duration {
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec // Jitter does not need secure randomness.
+}
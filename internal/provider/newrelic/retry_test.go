@@ -0,0 +1,189 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	nrErrors "github.com/newrelic/newrelic-client-go/v2/pkg/errors"
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen // Just a large test suite.
+func Test_Getting_external_metric_with_retries_enabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("retries_transient_errors_until_query_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{errs: []error{
+			nrErrors.NewUnexpectedStatusCode(503, "service unavailable"),
+			fmt.Errorf("reading response: %w", syscall.ECONNRESET),
+		}}
+
+		registry := metrics.NewKubeRegistry()
+
+		p := testProvider(t, retryProviderOptions(client, 3, registry.Register))
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if calls := client.calls(); calls != 3 {
+			t.Fatalf("Expected 3 queries, got %d", calls)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_query_retries_total [ALPHA] Total number of retried queries to the NewRelic backend by metric and class of the error.
+		# TYPE newrelic_adapter_external_provider_query_retries_total counter
+		newrelic_adapter_external_provider_query_retries_total{class="network",metric="test_metric"} 1
+		newrelic_adapter_external_provider_query_retries_total{class="server",metric="test_metric"} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_query_retries_total"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("fails_fast_on_non_retryable_error", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{errs: []error{nrErrors.NewUnauthorizedError(), nil}}
+
+		p := testProvider(t, retryProviderOptions(client, 3, nil))
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if calls := client.calls(); calls != 1 {
+			t.Fatalf("Expected 1 query, got %d", calls)
+		}
+	})
+
+	t.Run("gives_up_after_configured_number_of_retries", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{alwaysFail: nrErrors.NewUnexpectedStatusCode(429, "too many requests")}
+
+		p := testProvider(t, retryProviderOptions(client, 2, nil))
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if calls := client.calls(); calls != 3 {
+			t.Fatalf("Expected 3 queries, got %d", calls)
+		}
+	})
+
+	t.Run("does_not_retry_when_backoff_exceeds_request_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{alwaysFail: nrErrors.NewUnexpectedStatusCode(500, "internal server error")}
+
+		options := retryProviderOptions(client, 3, nil)
+		options.Retry.InitialBackoff = time.Minute
+
+		p := testProvider(t, options)
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if calls := client.calls(); calls != 1 {
+			t.Fatalf("Expected 1 query, got %d", calls)
+		}
+	})
+
+	t.Run("does_not_retry_when_retries_are_disabled", func(t *testing.T) {
+		t.Parallel()
+
+		client := &sequenceClient{alwaysFail: nrErrors.NewUnexpectedStatusCode(500, "internal server error")}
+
+		p := testProvider(t, retryProviderOptions(client, 0, nil))
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if calls := client.calls(); calls != 1 {
+			t.Fatalf("Expected 1 query, got %d", calls)
+		}
+	})
+}
+
+// sequenceClient returns configured errors in order and then successful responses,
+// unless it is configured to always fail.
+func Test_Retry_options_account_for_requests_retried_by_NerdGraph_client(t *testing.T) {
+	t.Parallel()
+
+	for retries, expected := range map[int]int{0: 4, 1: 8, 2: 12} {
+		options := newrelic.RetryOptions{MaxRetries: retries}
+
+		if requests := options.MaxRequests(); requests != expected {
+			t.Errorf("Expected %d requests with %d retries, got %d", expected, retries, requests)
+		}
+	}
+}
+
+type sequenceClient struct {
+	errs       []error
+	alwaysFail error
+	numCalls   int32
+}
+
+func (c *sequenceClient) QueryWithContext(_ context.Context, _ int, _ nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	call := int(atomic.AddInt32(&c.numCalls, 1)) - 1
+
+	if c.alwaysFail != nil {
+		return nil, c.alwaysFail
+	}
+
+	if call < len(c.errs) && c.errs[call] != nil {
+		return nil, c.errs[call]
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{{"value": float64(1)}},
+	}, nil
+}
+
+func (c *sequenceClient) calls() int {
+	return int(atomic.LoadInt32(&c.numCalls))
+}
+
+func retryProviderOptions(
+	client newrelic.NRDBClient, maxRetries int, registerFunc func(metrics.Registerable) error,
+) newrelic.ProviderOptions {
+	options, _ := testProviderOptions()
+	options.NRDBClient = client
+	options.RegisterFunc = registerFunc
+	options.Retry = newrelic.RetryOptions{
+		MaxRetries:     maxRetries,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+
+	return options
+}
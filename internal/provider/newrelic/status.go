@@ -0,0 +1,186 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+)
+
+// statusLatencyPercentiles are percentiles of query latencies reported by metric status.
+//
+//nolint:gochecknoglobals // Slices cannot be constants.
+var statusLatencyPercentiles = []float64{50, 90, 99}
+
+// MetricStatus describes the configuration of a metric and its recent queries.
+type MetricStatus struct {
+	Name string `json:"name"`
+	// Query is the configured NRQL query.
+	Query string `json:"query"`
+	// LastQuery is the latest query executed for the metric, including filters added by the adapter. It might
+	// be a query of a fallback.
+	LastQuery     string     `json:"lastQuery,omitempty"`
+	LastQueryTime *time.Time `json:"lastQueryTime,omitempty"`
+	// LastValue is the latest value served for the metric, which might come from a fallback.
+	LastValue          *float64   `json:"lastValue,omitempty"`
+	LastValueTimestamp *time.Time `json:"lastValueTimestamp,omitempty"`
+	LastSuccessTime    *time.Time `json:"lastSuccessTime,omitempty"`
+	LastError          string     `json:"lastError,omitempty"`
+	LastErrorClass     string     `json:"lastErrorClass,omitempty"`
+	LastErrorTime      *time.Time `json:"lastErrorTime,omitempty"`
+	// LatencySeconds holds percentiles of latencies of recent successful queries, e.g. "p99".
+	LatencySeconds map[string]float64 `json:"latencySeconds,omitempty"`
+	// EstimatedDailyQueries is the number of queries of the metric per day, including retried, hedged and
+	// fallback queries, extrapolated from recent queries.
+	EstimatedDailyQueries float64 `json:"estimatedDailyQueries"`
+	// EstimatedDailyInspectedEvents is the number of events inspected by these queries per day. It is zero
+	// unless performance statistics of queries are accounted.
+	EstimatedDailyInspectedEvents float64 `json:"estimatedDailyInspectedEvents"`
+}
+
+type metricStatusRecord struct {
+	lastQuery          Query
+	lastQueryTime      time.Time
+	lastValue          *float64
+	lastValueTimestamp *time.Time
+	latencies          *latencyWindow
+}
+
+// statusRecorder records the latest queries and values of each metric, so they can be inspected without
+// raising the log verbosity.
+type statusRecorder struct {
+	mu      sync.Mutex
+	metrics map[string]*metricStatusRecord
+}
+
+func newStatusRecorder() *statusRecorder {
+	return &statusRecorder{metrics: map[string]*metricStatusRecord{}}
+}
+
+// record returns the record of the given metric. It must be called with the lock held.
+func (r *statusRecorder) record(name string) *metricStatusRecord {
+	record, ok := r.metrics[name]
+	if !ok {
+		record = &metricStatusRecord{latencies: &latencyWindow{}}
+		r.metrics[name] = record
+	}
+
+	return record
+}
+
+func (r *statusRecorder) recordQuery(name string, query Query, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := r.record(name)
+	record.lastQuery = query
+	record.lastQueryTime = now
+}
+
+func (r *statusRecorder) recordLatency(name string, latency time.Duration) {
+	r.mu.Lock()
+	latencies := r.record(name).latencies
+	r.mu.Unlock()
+
+	latencies.record(latency)
+}
+
+func (r *statusRecorder) recordValue(name string, value float64, timestamp *time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := r.record(name)
+	record.lastValue = &value
+	record.lastValueTimestamp = timestamp
+}
+
+// snapshot returns a copy of the record of the given metric.
+func (r *statusRecorder) snapshot(name string) (metricStatusRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.metrics[name]
+	if !ok {
+		return metricStatusRecord{}, false
+	}
+
+	return *record, true
+}
+
+// MetricStatuses returns status of each metric configured in the given provider, sorted by name. If the given
+// provider is not a direct provider, nil is returned.
+func MetricStatuses(p provider.ExternalMetricsProvider) []MetricStatus {
+	dp, ok := p.(*directProvider)
+	if !ok {
+		return nil
+	}
+
+	statuses := make([]MetricStatus, 0, len(dp.metricsSupported))
+
+	for name, metric := range dp.metricsSupported {
+		statuses = append(statuses, dp.metricStatus(name, metric))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses
+}
+
+func (p *directProvider) metricStatus(name string, metric Metric) MetricStatus {
+	status := MetricStatus{
+		Name:  name,
+		Query: string(metric.Query),
+	}
+
+	if record, ok := p.status.snapshot(name); ok {
+		status.LastQuery = string(record.lastQuery)
+		status.LastQueryTime = timePointer(record.lastQueryTime)
+		status.LastValue = record.lastValue
+		status.LastValueTimestamp = record.lastValueTimestamp
+		status.LatencySeconds = latencyPercentiles(record.latencies)
+	}
+
+	status.EstimatedDailyQueries, status.EstimatedDailyInspectedEvents = p.costs.dailyEstimate(name, time.Now())
+
+	health := p.health.metric(name)
+	status.LastSuccessTime = timePointer(health.lastSuccess)
+
+	if !health.lastFailure.IsZero() {
+		status.LastError = health.lastError
+		status.LastErrorClass = health.lastErrorClass
+		status.LastErrorTime = timePointer(health.lastFailure)
+	}
+
+	return status
+}
+
+// latencyPercentiles returns reported percentiles of the given latencies in seconds, or nil when no
+// latencies were observed.
+func latencyPercentiles(latencies *latencyWindow) map[string]float64 {
+	percentiles := map[string]float64{}
+
+	for _, percentile := range statusLatencyPercentiles {
+		latency, observed := latencies.percentile(percentile, 1)
+		if !observed {
+			return nil
+		}
+
+		percentiles[fmt.Sprintf("p%g", percentile)] = latency.Seconds()
+	}
+
+	return percentiles
+}
+
+// timePointer returns pointer to the given time, or nil for zero time.
+func timePointer(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,381 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultThrottleMaxInterval is the longest interval between queries when none is configured.
+	DefaultThrottleMaxInterval = time.Minute
+
+	// throttleMinInterval is the interval between queries set by the first rate limited response. Once
+	// the interval gets shorter than that, throttling is lifted.
+	throttleMinInterval = 100 * time.Millisecond
+
+	// tooManyRequestsErrorClass is reported by NerdGraph in the body of rate limited responses with 200 status.
+	tooManyRequestsErrorClass = "TOO_MANY_REQUESTS"
+)
+
+// ThrottleOptions holds the configuration of adaptive throttling of queries rate limited by NerdGraph.
+type ThrottleOptions struct {
+	// MaxInterval is the longest interval between queries the throttle backs off to.
+	MaxInterval time.Duration
+}
+
+// Throttle adapts the rate of queries to the rate limiting responses of NerdGraph. NerdGraph rate limits
+// each account separately, so queries of each account are throttled independently. Each rate limited
+// response doubles the interval enforced between queries of the account and blocks them for the period
+// requested by the Retry-After header, while each successful query shortens the interval until throttling
+// is lifted.
+type Throttle struct {
+	maxInterval time.Duration
+
+	mu       sync.Mutex
+	accounts map[int]*accountThrottle
+}
+
+// accountThrottle holds the throttling state of a single account.
+type accountThrottle struct {
+	interval     time.Duration
+	blockedUntil time.Time
+	next         time.Time
+}
+
+// NewThrottle creates a throttle, which should be fed by the transport returned by Transport method, so
+// responses with 429 status are recorded as well.
+func NewThrottle(options ThrottleOptions) *Throttle {
+	maxInterval := options.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultThrottleMaxInterval
+	}
+
+	return &Throttle{
+		maxInterval: maxInterval,
+		accounts:    map[int]*accountThrottle{},
+	}
+}
+
+// Transport returns a round tripper recording rate limited responses of NerdGraph in the throttle.
+func (t *Throttle) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &throttleTransport{
+		next:     next,
+		throttle: t,
+	}
+}
+
+// account returns the throttling state of the given account. Must be called with the mutex held.
+func (t *Throttle) account(accountID int) *accountThrottle {
+	state, ok := t.accounts[accountID]
+	if !ok {
+		state = &accountThrottle{}
+		t.accounts[accountID] = state
+	}
+
+	return state
+}
+
+// recordRateLimited backs off the rate of queries of the given account after rate limited response.
+func (t *Throttle) recordRateLimited(accountID int, now time.Time, retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.account(accountID)
+
+	state.interval = min(max(2*state.interval, throttleMinInterval), t.maxInterval)
+
+	if retryAfter <= 0 {
+		retryAfter = state.interval
+	}
+
+	if blockedUntil := now.Add(retryAfter); blockedUntil.After(state.blockedUntil) {
+		state.blockedUntil = blockedUntil
+	}
+
+	klog.InfoS("NerdGraph rate limited queries, pausing and throttling queries", logkeys.AccountID, accountID,
+		logkeys.Delay, retryAfter, logkeys.Interval, state.interval)
+}
+
+// recordSuccess speeds up the rate of queries of the given account after successful query.
+func (t *Throttle) recordSuccess(accountID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.accounts[accountID]
+	if !ok || state.interval == 0 {
+		return
+	}
+
+	state.interval -= state.interval / 4
+
+	if state.interval < throttleMinInterval {
+		delete(t.accounts, accountID)
+
+		klog.InfoS("NerdGraph no longer rate limits queries, throttling lifted", logkeys.AccountID, accountID)
+	}
+}
+
+// throttleTurn is the turn of a query reserved in the throttle.
+type throttleTurn struct {
+	accountID int
+	start     time.Time
+	end       time.Time
+}
+
+// reserve returns the turn of the query of the given account and how long the query has to wait for it.
+// The turn is taken only if the wait does not exceed the given limit.
+func (t *Throttle) reserve(accountID int, now time.Time, limit time.Duration) (throttleTurn, time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.accounts[accountID]
+	if !ok {
+		return throttleTurn{}, 0, true
+	}
+
+	start := now
+
+	if state.blockedUntil.After(start) {
+		start = state.blockedUntil
+	}
+
+	if state.next.After(start) {
+		start = state.next
+	}
+
+	delay := start.Sub(now)
+	if delay > limit {
+		return throttleTurn{}, delay, false
+	}
+
+	turn := throttleTurn{accountID: accountID, start: start, end: start}
+
+	if state.interval > 0 {
+		turn.end = start.Add(state.interval)
+		state.next = turn.end
+	}
+
+	return turn, delay, true
+}
+
+// release gives up the given turn of a query which is no longer going to be executed, so the next query
+// can take it. Turns reserved after the given one are kept, so only the latest turn can be given up.
+func (t *Throttle) release(turn throttleTurn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if state, ok := t.accounts[turn.accountID]; ok && state.next.Equal(turn.end) {
+		state.next = turn.start
+	}
+}
+
+// currentInterval returns the longest interval enforced between queries of any account.
+func (t *Throttle) currentInterval() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	interval := time.Duration(0)
+
+	for _, state := range t.accounts {
+		interval = max(interval, state.interval)
+	}
+
+	return interval
+}
+
+type throttleTransport struct {
+	next     http.RoundTripper
+	throttle *Throttle
+}
+
+// RoundTrip records responses with 429 status for the account queried by the request. Rate limiting reported
+// by NerdGraph in the body of responses with 200 status is recorded by the throttling client once the client
+// parses the response, so the body is not read twice.
+func (tt *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, accountID, err := requestAccountID(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+
+	resp, err := tt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Transport should not alter errors.
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return resp, nil
+	}
+
+	if accountID == 0 {
+		klog.V(debug).InfoS("NerdGraph rate limited request not querying any account, not throttling queries")
+
+		return resp, nil
+	}
+
+	tt.throttle.recordRateLimited(accountID, time.Now(), retryAfter(resp.Header.Get("Retry-After")))
+
+	return resp, nil
+}
+
+// requestAccountID returns the account queried by the given NerdGraph request, which is 0 if the request does
+// not query any account. The NerdGraph client does not pass the context of queries to requests, so the account
+// is read from the variables in the request body. The returned request holds a copy of the read body.
+func requestAccountID(req *http.Request) (*http.Request, int, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, 0, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if closeErr := req.Body.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, 0, err //nolint:wrapcheck // Error is wrapped by the caller.
+	}
+
+	// Request must not be modified by the transport, other than consuming its body.
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	graphQLRequest := struct {
+		Variables struct {
+			AccountID int `json:"accountId"`
+		} `json:"variables"`
+	}{}
+
+	if err := json.Unmarshal(body, &graphQLRequest); err != nil {
+		return req, 0, nil
+	}
+
+	return req, graphQLRequest.Variables.AccountID, nil
+}
+
+// graphQLRateLimited returns true when the given error holds NerdGraph errors with TOO_MANY_REQUESTS error class.
+func graphQLRateLimited(err error) bool {
+	var retryable retryableError
+	if !errors.As(err, &retryable) {
+		return false
+	}
+
+	for _, graphQLErr := range graphQLErrors(retryable) {
+		if graphQLErr.Extensions.ErrorClass == tooManyRequestsErrorClass {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfter parses the value of the Retry-After header, which is either a number of seconds or a date.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+// throttlingClient delays queries according to the throttle. Queries which would be delayed beyond the
+// request context deadline are rejected with TooManyRequests API status.
+type throttlingClient struct {
+	client   NRDBClient
+	throttle *Throttle
+	metrics  providerMetrics
+}
+
+func newThrottlingClient(client NRDBClient, throttle *Throttle, metrics providerMetrics) *throttlingClient {
+	return &throttlingClient{
+		client:   client,
+		throttle: throttle,
+		metrics:  metrics,
+	}
+}
+
+func (c *throttlingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	defer func() {
+		c.metrics.throttleInterval.Set(c.throttle.currentInterval().Seconds())
+	}()
+
+	if err := c.wait(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	result, err := c.client.QueryWithContext(ctx, accountID, query)
+
+	switch {
+	case err == nil:
+		c.throttle.recordSuccess(accountID)
+	case graphQLRateLimited(err):
+		c.throttle.recordRateLimited(accountID, time.Now(), 0)
+	}
+
+	return result, err //nolint:wrapcheck // Decorator should not alter errors.
+}
+
+func (c *throttlingClient) wait(ctx context.Context, accountID int) error {
+	name := metricFromContext(ctx)
+
+	limit := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		limit = time.Until(deadline)
+	}
+
+	turn, delay, ok := c.throttle.reserve(accountID, time.Now(), limit)
+	if delay == 0 {
+		return nil
+	}
+
+	c.metrics.throttledQueriesTotal.WithLabelValues(name).Inc()
+
+	if !ok {
+		return apierrors.NewTooManyRequests(
+			fmt.Sprintf("queries are throttled due to NerdGraph rate limiting, retry in %v", delay.Round(time.Millisecond)),
+			int(math.Ceil(delay.Seconds())),
+		)
+	}
+
+	klog.V(debug).InfoS("Delaying query due to NerdGraph rate limiting", logkeys.Metric, name, logkeys.Delay, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		c.throttle.release(turn)
+
+		return fmt.Errorf("waiting for throttled query turn: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const tooManyRequestsBody = `{"errors":[{"message":"Too many requests","extensions":{"errorClass":"TOO_MANY_REQUESTS"}}]}`
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_with_throttle(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("rejects_queries_blocked_by_retry_after_header_of_rate_limited_response", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{})
+
+		sendThrottledRequest(ctx, t, throttle, testAccountID, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.Throttle = throttle
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected too many requests error, got: %v", err)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_throttle_interval_seconds [ALPHA] Interval in seconds enforced between queries to the NewRelic backend due to rate limiting responses, 0 when not throttled.
+		# TYPE newrelic_adapter_external_provider_throttle_interval_seconds gauge
+		newrelic_adapter_external_provider_throttle_interval_seconds 0.1
+		# HELP newrelic_adapter_external_provider_throttled_queries_total [ALPHA] Total number of queries to the NewRelic backend delayed or rejected due to rate limiting responses.
+		# TYPE newrelic_adapter_external_provider_throttled_queries_total counter
+		newrelic_adapter_external_provider_throttled_queries_total{metric="test_metric"} 1
+		`
+
+		metricNames := []string{
+			"newrelic_adapter_external_provider_throttle_interval_seconds",
+			"newrelic_adapter_external_provider_throttled_queries_total",
+		}
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricNames...); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("detects_rate_limiting_reported_in_error_class_of_response_body", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{})
+
+		options, _ := testProviderOptions()
+		options.NRDBClient = &sequenceClient{errs: []error{
+			newGraphQLError("Too many requests", "TOO_MANY_REQUESTS", ""),
+		}}
+		options.Throttle = throttle
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if !apierrors.IsTooManyRequests(err) {
+			t.Fatalf("Expected too many requests error, got: %v", err)
+		}
+	})
+
+	t.Run("does_not_read_body_of_responses_with_200_status", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{})
+
+		body := sendThrottledRequest(ctx, t, throttle, testAccountID, func(w http.ResponseWriter, _ *http.Request) {
+			if _, err := w.Write([]byte(tooManyRequestsBody)); err != nil {
+				t.Errorf("Writing response: %v", err)
+			}
+		})
+
+		if body != tooManyRequestsBody {
+			t.Fatalf("Expected response body to be preserved, got %q", body)
+		}
+
+		options, _ := testProviderOptions()
+		options.Throttle = throttle
+
+		p := testProvider(t, options)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("delays_queries_blocked_within_request_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{})
+
+		sendThrottledRequest(ctx, t, throttle, testAccountID, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		options, _ := testProviderOptions()
+		options.Throttle = throttle
+
+		p := testProvider(t, options)
+
+		start := time.Now()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("Expected query to be delayed, took %v", elapsed)
+		}
+	})
+
+	t.Run("does_not_throttle_queries_of_other_accounts", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{})
+
+		sendThrottledRequest(ctx, t, throttle, testAccountID+1, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		options, _ := testProviderOptions()
+		options.Throttle = throttle
+
+		p := testProvider(t, options)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("releases_turn_of_query_canceled_while_waiting", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{MaxInterval: 200 * time.Millisecond})
+
+		// Two rate limited responses set the interval between queries to 200ms.
+		for i := 0; i < 2; i++ {
+			sendThrottledRequest(ctx, t, throttle, testAccountID, func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			})
+		}
+
+		options, _ := testProviderOptions()
+		options.Throttle = throttle
+
+		p := testProvider(t, options)
+
+		// Wait for the block requested by the rate limited responses to pass, so only the interval applies.
+		time.Sleep(250 * time.Millisecond)
+
+		// First query takes the current turn, the second one waits for the next turn and gives up.
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		canceledCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+
+		_, err := p.GetExternalMetric(canceledCtx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+		if err == nil {
+			t.Fatalf("Expected error of canceled query")
+		}
+
+		// Without the canceled query releasing its turn, the next query would wait for the turn after it.
+		start := time.Now()
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+			t.Fatalf("Expected query to take turn of canceled query, waited %v", elapsed)
+		}
+	})
+
+	t.Run("lifts_throttling_after_successful_responses", func(t *testing.T) {
+		t.Parallel()
+
+		throttle := newrelic.NewThrottle(newrelic.ThrottleOptions{})
+
+		sendThrottledRequest(ctx, t, throttle, testAccountID, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.Throttle = throttle
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		// Wait for the block requested by the rate limited response to pass.
+		time.Sleep(150 * time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+
+			_, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName})
+
+			cancel()
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_throttle_interval_seconds [ALPHA] Interval in seconds enforced between queries to the NewRelic backend due to rate limiting responses, 0 when not throttled.
+		# TYPE newrelic_adapter_external_provider_throttle_interval_seconds gauge
+		newrelic_adapter_external_provider_throttle_interval_seconds 0
+		`
+
+		metricName := "newrelic_adapter_external_provider_throttle_interval_seconds"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+}
+
+// sendThrottledRequest sends a request querying the given account through the throttle transport to a server
+// using given handler and returns the response body.
+func sendThrottledRequest(
+	ctx context.Context, t *testing.T, throttle *newrelic.Throttle, accountID int, handler http.HandlerFunc,
+) string {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: throttle.Transport(nil)}
+
+	body := fmt.Sprintf(`{"query":"query","variables":{"accountId":%d}}`, accountID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Creating request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Sending request: %v", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck // We don't care about closing errors in tests.
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Reading response body: %v", err)
+	}
+
+	return string(respBody)
+}
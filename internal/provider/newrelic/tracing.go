@@ -0,0 +1,74 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/tracing"
+)
+
+const (
+	tracerName = "github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+
+	// Attributes of spans created by the provider.
+	nrqlKey      = attribute.Key("newrelic.nrql")
+	accountIDKey = attribute.Key("newrelic.account_id")
+	sourceKey    = attribute.Key("external_metric.source")
+)
+
+// nrqlLiteral matches string and numeric literals of NRQL queries, which may hold sensitive values.
+//
+//nolint:gochecknoglobals // Compiled once.
+var nrqlLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|\b\d+(?:\.\d+)?\b`)
+
+// redactQuery replaces literals of the given query with placeholders, keeping the shape of the query.
+func redactQuery(query string) string {
+	return nrqlLiteral.ReplaceAllString(query, "?")
+}
+
+// tracingClient records a span for each query sent to the NRDB client.
+type tracingClient struct {
+	client        NRDBClient
+	tracer        trace.Tracer
+	redactQueries bool
+}
+
+func newTracingClient(client NRDBClient, tracer trace.Tracer, redactQueries bool) *tracingClient {
+	return &tracingClient{
+		client:        client,
+		tracer:        tracer,
+		redactQueries: redactQueries,
+	}
+}
+
+func (c *tracingClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) { //nolint:lll // External interface requirement.
+	nrql := string(query)
+	if c.redactQueries {
+		nrql = redactQuery(nrql)
+	}
+
+	ctx, span := c.tracer.Start(ctx, "NRDB.QueryWithContext", trace.WithAttributes(
+		tracing.MetricKey.String(metricFromContext(ctx)),
+		accountIDKey.Int(accountID),
+		nrqlKey.String(nrql),
+	))
+
+	result, err := c.client.QueryWithContext(ctx, accountID, query)
+
+	spanErr := err
+	if err != nil && c.redactQueries {
+		// Messages of NerdGraph errors may quote the query, so only the class of the error is recorded.
+		spanErr = &queryError{err: err, class: classifyError(err)}
+	}
+
+	tracing.End(span, spanErr, c.redactQueries)
+
+	return result, err //nolint:wrapcheck // Decorator should not alter errors.
+}
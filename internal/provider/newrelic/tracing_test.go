@@ -0,0 +1,194 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_with_tracer_provider(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("records_span_of_query_as_child_of_span_of_request", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracetest.NewSpanRecorder()
+
+		options, _ := testProviderOptions()
+		options.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		p := testProvider(t, options)
+
+		sl, err := labels.Parse("app=foo")
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		if _, err := p.GetExternalMetric(ctx, "", sl, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		request := endedSpan(t, recorder, "DirectProvider.GetExternalMetric")
+		query := endedSpan(t, recorder, "NRDB.QueryWithContext")
+
+		if query.Parent().SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("Expected query span to be child of request span")
+		}
+
+		expectedRequestAttributes := map[attribute.Key]string{
+			"external_metric.name":     testMetricName,
+			"external_metric.selector": "app=foo",
+			"external_metric.source":   "query",
+		}
+
+		for key, expected := range expectedRequestAttributes {
+			if value := spanAttribute(request, key); value != expected {
+				t.Errorf("Expected attribute %q of request span to be %q, got %q", key, expected, value)
+			}
+		}
+
+		nrql := spanAttribute(query, "newrelic.nrql")
+		if !strings.HasPrefix(nrql, testQuery) || !strings.Contains(nrql, "'foo'") {
+			t.Errorf("Expected query span to contain executed query, got %q", nrql)
+		}
+	})
+
+	t.Run("redacts_literals_of_recorded_queries_when_configured", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracetest.NewSpanRecorder()
+
+		options, _ := testProviderOptions()
+		options.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		options.RedactTracedQueries = true
+
+		p := testProvider(t, options)
+
+		sl, err := labels.Parse("app=foo")
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		if _, err := p.GetExternalMetric(ctx, "", sl, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		nrql := spanAttribute(endedSpan(t, recorder, "NRDB.QueryWithContext"), "newrelic.nrql")
+
+		if strings.Contains(nrql, "foo") || !strings.Contains(nrql, "limit ?") {
+			t.Errorf("Expected literals of recorded query to be redacted, got %q", nrql)
+		}
+	})
+
+	t.Run("records_neither_query_nor_selector_of_failed_query_when_redacting", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracetest.NewSpanRecorder()
+
+		options, client := testProviderOptions()
+		options.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		options.RedactTracedQueries = true
+		client.err = newGraphQLError("NRQL Syntax Error: Error at line 1, unexpected 'secret'", "", "")
+		client.response = nil
+
+		p := testProvider(t, options)
+
+		sl, err := labels.Parse("app=secret")
+		if err != nil {
+			t.Fatalf("Parsing selector: %v", err)
+		}
+
+		if _, err := p.GetExternalMetric(ctx, "", sl, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		for _, name := range []string{"DirectProvider.GetExternalMetric", "NRDB.QueryWithContext"} {
+			span := endedSpan(t, recorder, name)
+
+			recorded := []string{span.Status().Description}
+
+			for _, kv := range span.Attributes() {
+				recorded = append(recorded, kv.Value.Emit())
+			}
+
+			for _, event := range span.Events() {
+				recorded = append(recorded, event.Name)
+
+				for _, kv := range event.Attributes {
+					recorded = append(recorded, kv.Value.Emit())
+				}
+			}
+
+			for _, value := range recorded {
+				if strings.Contains(value, "secret") || strings.Contains(value, testQuery) {
+					t.Errorf("Expected span %q to not contain query or selector, got %q", name, value)
+				}
+			}
+
+			if errorType := spanAttribute(span, "error.type"); errorType != "nrql_syntax" {
+				t.Errorf("Expected span %q to record class of error, got %q", name, errorType)
+			}
+		}
+	})
+
+	t.Run("marks_spans_as_failed_when_query_fails", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracetest.NewSpanRecorder()
+
+		options := fallbackProviderOptions()
+		options.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		for _, name := range []string{"DirectProvider.GetExternalMetric", "NRDB.QueryWithContext"} {
+			if status := endedSpan(t, recorder, name).Status(); status.Code != codes.Error {
+				t.Errorf("Expected span %q to have error status, got %v", name, status)
+			}
+		}
+	})
+}
+
+// endedSpan returns the ended span of the given name recorded by the recorder.
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	t.Fatalf("Span %q not recorded", name)
+
+	return nil
+}
+
+// spanAttribute returns the value of the given attribute of the span formatted as string.
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultValueMetricsMaxSelectors is the default number of selectors per metric exported when
+	// exporting values by selector.
+	DefaultValueMetricsMaxSelectors = 20
+
+	// otherSelectorsLabel is the selector label of metric values which selectors exceed the limit.
+	otherSelectorsLabel = "other"
+)
+
+// ValueMetricsOptions holds the configuration of exporting served values of metrics.
+type ValueMetricsOptions struct {
+	// BySelector exports values per selector of the request, instead of the latest value of any selector.
+	BySelector bool
+	// MaxSelectors limits the number of selectors exported per metric when BySelector is set. Values of
+	// further selectors are exported with "other" selector. Defaults to DefaultValueMetricsMaxSelectors.
+	MaxSelectors int
+}
+
+// valueRecorder exports the latest served value, the timestamp of its sample and the time of the latest
+// successful query of each metric. The timestamp is exported rather than the age of the sample, so the age
+// computed from it keeps growing while no value is served, e.g. during an outage.
+type valueRecorder struct {
+	options ValueMetricsOptions
+	metrics providerMetrics
+
+	mu sync.Mutex
+	// selectors holds the selectors exported for each metric.
+	selectors map[string]map[string]struct{}
+}
+
+func newValueRecorder(options ValueMetricsOptions, metrics providerMetrics) *valueRecorder {
+	if options.MaxSelectors <= 0 {
+		options.MaxSelectors = DefaultValueMetricsMaxSelectors
+	}
+
+	return &valueRecorder{
+		options:   options,
+		metrics:   metrics,
+		selectors: map[string]map[string]struct{}{},
+	}
+}
+
+// recordQuery records the time of a successful query of the given metric.
+func (r *valueRecorder) recordQuery(name string, sl labels.Selector, now time.Time) {
+	r.metrics.lastSuccessfulQuery.WithLabelValues(name, r.selectorLabel(name, sl)).Set(float64(now.Unix()))
+}
+
+// recordValue records a value served for the given metric. Values without timestamp are considered fresh.
+func (r *valueRecorder) recordValue(
+	name string, sl labels.Selector, value float64, timestamp *time.Time, now time.Time,
+) {
+	selector := r.selectorLabel(name, sl)
+
+	sampledAt := now
+	if timestamp != nil {
+		sampledAt = *timestamp
+	}
+
+	r.metrics.servedValue.WithLabelValues(name, selector).Set(value)
+	r.metrics.sampleTimestamp.WithLabelValues(name, selector).Set(float64(sampledAt.UnixMilli()) / 1000)
+}
+
+// selectorLabel returns the selector label for the given metric, keeping the number of selectors per metric
+// within the limit.
+func (r *valueRecorder) selectorLabel(name string, sl labels.Selector) string {
+	if !r.options.BySelector || sl == nil {
+		return ""
+	}
+
+	selector := sl.String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	selectors, ok := r.selectors[name]
+	if !ok {
+		selectors = map[string]struct{}{}
+		r.selectors[name] = selectors
+	}
+
+	if _, ok := selectors[selector]; ok {
+		return selector
+	}
+
+	if len(selectors) >= r.options.MaxSelectors {
+		klog.V(debug).InfoS("Limit of selectors reached, exporting value of selector as \"other\"",
+			logkeys.Metric, name, logkeys.Selector, selector, logkeys.Limit, r.options.MaxSelectors)
+
+		return otherSelectorsLabel
+	}
+
+	selectors[selector] = struct{}{}
+
+	return selector
+}
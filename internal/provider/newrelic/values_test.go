@@ -0,0 +1,181 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package newrelic_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/component-base/metrics"
+	metricsTestutil "k8s.io/component-base/metrics/testutil"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Getting_external_metric_exports(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.ContextWithDeadline(t)
+
+	t.Run("latest_served_value_sample_timestamp_and_successful_query_time", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_value [ALPHA] Latest value served for the external metric.
+		# TYPE newrelic_adapter_external_provider_value gauge
+		newrelic_adapter_external_provider_value{metric="test_metric",selector=""} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_value"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+
+		for _, name := range []string{
+			"newrelic_adapter_external_provider_sample_timestamp_seconds",
+			"newrelic_adapter_external_provider_last_successful_query_timestamp_seconds",
+		} {
+			if series := gatheredSeries(t, registry, name); series != 1 {
+				t.Errorf("Expected 1 series of %q, got %d", name, series)
+			}
+		}
+	})
+
+	t.Run("timestamp_of_served_sample", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, client := testProviderOptions()
+		options.RegisterFunc = registry.Register
+
+		sampledAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+		client.response.Results[0]["timestamp"] = float64(sampledAt.UnixMilli())
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		//nolint:lll // Expected metrics are not wrapped.
+		expected := fmt.Sprintf(`
+		# HELP newrelic_adapter_external_provider_sample_timestamp_seconds [ALPHA] Unix timestamp in seconds of the sample of the latest value served for the external metric, or of the time it was served if the sample has no timestamp.
+		# TYPE newrelic_adapter_external_provider_sample_timestamp_seconds gauge
+		newrelic_adapter_external_provider_sample_timestamp_seconds{metric="test_metric",selector=""} %d
+		`, sampledAt.Unix())
+
+		metricName := "newrelic_adapter_external_provider_sample_timestamp_seconds"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+
+	t.Run("value_served_by_fallback_without_successful_query_time", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options := fallbackProviderOptions(newrelic.Fallback{Value: ptr.To(42.0)})
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		if _, err := p.GetExternalMetric(ctx, "", nil, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_value [ALPHA] Latest value served for the external metric.
+		# TYPE newrelic_adapter_external_provider_value gauge
+		newrelic_adapter_external_provider_value{metric="test_metric",selector=""} 42
+		`
+
+		metricName := "newrelic_adapter_external_provider_value"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+
+		name := "newrelic_adapter_external_provider_last_successful_query_timestamp_seconds"
+
+		if series := gatheredSeries(t, registry, name); series != 0 {
+			t.Errorf("Expected no series of %q, got %d", name, series)
+		}
+	})
+
+	t.Run("values_per_selector_up_to_configured_limit", func(t *testing.T) {
+		t.Parallel()
+
+		registry := metrics.NewKubeRegistry()
+
+		options, _ := testProviderOptions()
+		options.ValueMetrics = newrelic.ValueMetricsOptions{BySelector: true, MaxSelectors: 1}
+		options.RegisterFunc = registry.Register
+
+		p := testProvider(t, options)
+
+		for _, selector := range []string{"app=foo", "app=bar", "app=baz"} {
+			sl, err := labels.Parse(selector)
+			if err != nil {
+				t.Fatalf("Parsing selector: %v", err)
+			}
+
+			if _, err := p.GetExternalMetric(ctx, "", sl, provider.ExternalMetricInfo{Metric: testMetricName}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		expected := `
+		# HELP newrelic_adapter_external_provider_value [ALPHA] Latest value served for the external metric.
+		# TYPE newrelic_adapter_external_provider_value gauge
+		newrelic_adapter_external_provider_value{metric="test_metric",selector="app=foo"} 1
+		newrelic_adapter_external_provider_value{metric="test_metric",selector="other"} 1
+		`
+
+		metricName := "newrelic_adapter_external_provider_value"
+
+		if err := metricsTestutil.GatherAndCompare(registry, bytes.NewBufferString(expected), metricName); err != nil {
+			t.Fatalf("Unexpected metric value: %v", err)
+		}
+	})
+}
+
+// gatheredSeries returns the number of series of the given metric gathered from the registry.
+func gatheredSeries(t *testing.T, registry metrics.KubeRegistry, name string) int {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gathering metrics: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() == name {
+			return len(family.GetMetric())
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing holds OpenTelemetry helpers shared by the providers, so spans of a single request
+// carry consistent attributes.
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Attributes of spans created by the providers.
+const (
+	// MetricKey holds the name of the requested external metric.
+	MetricKey = attribute.Key("external_metric.name")
+	// SelectorKey holds the label selector of the request.
+	SelectorKey = attribute.Key("external_metric.selector")
+	// ErrorTypeKey holds the class of the error of a failed span, recorded instead of the error when redacting.
+	ErrorTypeKey = attribute.Key("error.type")
+
+	// unknownErrorType is recorded for redacted errors which class is not known.
+	unknownErrorType = "unknown"
+)
+
+// classifiedError is implemented by errors of the providers, which know their class.
+type classifiedError interface {
+	ErrorClass() string
+}
+
+// RequestAttributes returns attributes describing a request for the given external metric and selector.
+// When redact is true, the selector is omitted, as its values may be sensitive.
+func RequestAttributes(metric string, selector labels.Selector, redact bool) trace.SpanStartEventOption {
+	if redact {
+		return trace.WithAttributes(MetricKey.String(metric))
+	}
+
+	selectorValue := ""
+	if selector != nil {
+		selectorValue = selector.String()
+	}
+
+	return trace.WithAttributes(MetricKey.String(metric), SelectorKey.String(selectorValue))
+}
+
+// Tracer returns a tracer of the given instrumentation scope from the given provider. When provider
+// is nil, a tracer not recording any spans is returned.
+func Tracer(tracerProvider trace.TracerProvider, name string) trace.Tracer {
+	if tracerProvider == nil {
+		tracerProvider = noop.NewTracerProvider()
+	}
+
+	return tracerProvider.Tracer(name)
+}
+
+// End marks the span as failed when the error is not nil and ends it. When redact is true, only the class
+// of the error is recorded, as error messages may hold the query and the selector.
+func End(span trace.Span, err error, redact bool) {
+	switch {
+	case err == nil:
+	case redact:
+		errorType := unknownErrorType
+
+		var classified classifiedError
+		if errors.As(err, &classified) {
+			errorType = classified.ErrorClass()
+		}
+
+		span.SetAttributes(ErrorTypeKey.String(errorType))
+		span.SetStatus(codes.Error, errorType)
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package status implements a page listing every configured metric with its query, latest value and error,
+// query latencies, cache statistics and HPAs using it, so the adapter can be debugged without raising the
+// log verbosity.
+package status
+
+import (
+	_ "embed" // Embeds the page template.
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/labels"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+)
+
+// Path is the path under which the status handler should be served.
+//
+// The page is rendered as HTML, unless JSON is requested using "format=json" query parameter or the Accept header.
+const Path = "/debug/status"
+
+//go:embed status.html
+var pageTemplate string
+
+//nolint:gochecknoglobals // Parsed once.
+var page = template.Must(template.New("status").Funcs(template.FuncMap{
+	"formatTime": formatTime,
+}).Parse(pageTemplate))
+
+// Options holds the configuration of the status handler.
+type Options struct {
+	// DirectProvider is the provider executing queries of the metrics.
+	DirectProvider provider.ExternalMetricsProvider
+	// CacheProvider is the provider caching values of the metrics. If it is not a cache provider, e.g. because
+	// caching is disabled, cache statistics are not listed.
+	CacheProvider provider.ExternalMetricsProvider
+	// HPALister lists HPAs using the metrics. If nil, HPAs are not listed.
+	HPALister autoscalingv2listers.HorizontalPodAutoscalerLister
+}
+
+// Status is the status of the adapter.
+type Status struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Metrics     []MetricStatus `json:"metrics"`
+	// Errors holds errors of listing parts of the status, which is listed as complete as possible.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// MetricStatus is the status of a single metric.
+type MetricStatus struct {
+	newrelic.MetricStatus
+	Cache *cache.MetricStats `json:"cache,omitempty"`
+	// HPAs are the HPAs referencing the metric as namespace/name.
+	HPAs []string `json:"hpas,omitempty"`
+}
+
+type handler struct {
+	options Options
+}
+
+// Handler returns HTTP handler serving the status of the adapter. If the direct provider is not configured,
+// nil is returned.
+func Handler(options Options) http.Handler {
+	if newrelic.MetricStatuses(options.DirectProvider) == nil {
+		return nil
+	}
+
+	return &handler{options: options}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	status := h.status(r)
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			klog.ErrorS(err, "Writing response")
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := page.Execute(w, status); err != nil {
+		klog.ErrorS(err, "Rendering status page")
+	}
+}
+
+func (h *handler) status(r *http.Request) Status {
+	status := Status{GeneratedAt: time.Now()}
+
+	cacheStats, err := cache.Stats(r.Context(), h.options.CacheProvider)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("listing cache statistics: %v", err))
+	}
+
+	hpas, err := h.hpasByMetric()
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("listing HPAs: %v", err))
+	}
+
+	for _, metricStatus := range newrelic.MetricStatuses(h.options.DirectProvider) {
+		s := MetricStatus{MetricStatus: metricStatus, HPAs: hpas[metricStatus.Name]}
+
+		if cacheStats != nil {
+			stats := cacheStats[metricStatus.Name]
+			s.Cache = &stats
+		}
+
+		status.Metrics = append(status.Metrics, s)
+	}
+
+	return status
+}
+
+// hpasByMetric returns names of HPAs referencing each external metric.
+func (h *handler) hpasByMetric() (map[string][]string, error) {
+	if h.options.HPALister == nil {
+		return nil, nil
+	}
+
+	hpas, err := h.options.HPALister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("listing from cache: %w", err)
+	}
+
+	byMetric := map[string][]string{}
+
+	for _, hpa := range hpas {
+		for _, spec := range hpa.Spec.Metrics {
+			if spec.Type != autoscalingv2.ExternalMetricSourceType || spec.External == nil {
+				continue
+			}
+
+			name := spec.External.Metric.Name
+			byMetric[name] = append(byMetric[name], hpa.Namespace+"/"+hpa.Name)
+		}
+	}
+
+	for _, names := range byMetric {
+		sort.Strings(names)
+	}
+
+	return byMetric, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
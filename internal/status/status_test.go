@@ -0,0 +1,217 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package status_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	cacheprovider "github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/mock"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/status"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+const (
+	testMetric = "test_metric"
+	testQuery  = "select test from testSample"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Status_handler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists_query_value_latencies_cache_statistics_and_HPAs_of_each_metric_as_JSON", func(t *testing.T) {
+		t.Parallel()
+
+		handler := testHandler(t)
+
+		response := serve(t, handler, http.MethodGet, status.Path+"?format=json")
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, response.Code, response.Body)
+		}
+
+		got := status.Status{}
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Decoding status: %v", err)
+		}
+
+		if len(got.Metrics) != 2 || got.Metrics[0].Name != "other_metric" || got.Metrics[1].Name != testMetric {
+			t.Fatalf("Expected status of all configured metrics sorted by name, got %+v", got.Metrics)
+		}
+
+		metricStatus := got.Metrics[1]
+
+		if metricStatus.Query != testQuery || !strings.Contains(metricStatus.LastQuery, "`app` = 'foo'") {
+			t.Errorf("Expected configured and executed query, got %q and %q", metricStatus.Query, metricStatus.LastQuery)
+		}
+
+		if metricStatus.LastValue == nil || *metricStatus.LastValue != 1 || metricStatus.LastSuccessTime == nil {
+			t.Errorf("Expected last value and time of successful query, got %+v", metricStatus)
+		}
+
+		if _, ok := metricStatus.LatencySeconds["p99"]; !ok {
+			t.Errorf("Expected latency percentiles, got %v", metricStatus.LatencySeconds)
+		}
+
+		if metricStatus.Cache == nil || metricStatus.Cache.Entries != 1 || metricStatus.Cache.Hits != 1 {
+			t.Errorf("Expected 1 cache entry with 1 hit, got %+v", metricStatus.Cache)
+		}
+
+		if len(metricStatus.HPAs) != 1 || metricStatus.HPAs[0] != "default/test-hpa" {
+			t.Errorf("Expected HPA using the metric, got %v", metricStatus.HPAs)
+		}
+
+		if otherStatus := got.Metrics[0]; otherStatus.LastQuery != "" || len(otherStatus.HPAs) != 0 {
+			t.Errorf("Expected no queries and HPAs of other metric, got %+v", otherStatus)
+		}
+	})
+
+	t.Run("renders_HTML_page_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		response := serve(t, testHandler(t), http.MethodGet, status.Path)
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, response.Code, response.Body)
+		}
+
+		if contentType := response.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
+			t.Errorf("Expected HTML content type, got %q", contentType)
+		}
+
+		for _, expected := range []string{testMetric, testQuery, "default/test-hpa"} {
+			if !strings.Contains(response.Body.String(), expected) {
+				t.Errorf("Expected page to contain %q, got:\n%s", expected, response.Body)
+			}
+		}
+	})
+
+	t.Run("rejects_methods_other_than_GET", func(t *testing.T) {
+		t.Parallel()
+
+		if response := serve(t, testHandler(t), http.MethodPost, status.Path); response.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("Expected status code %d, got %d", http.StatusMethodNotAllowed, response.Code)
+		}
+	})
+}
+
+func Test_Status_handler_is_not_returned_without_direct_provider(t *testing.T) {
+	t.Parallel()
+
+	if handler := status.Handler(status.Options{DirectProvider: &mock.Provider{}}); handler != nil {
+		t.Fatalf("Expected no handler, got %v", handler)
+	}
+}
+
+// testHandler returns status handler of providers which served the test metric twice.
+func testHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	directProvider, err := newrelic.NewDirectProvider(newrelic.ProviderOptions{
+		ExternalMetrics: map[string]newrelic.Metric{
+			testMetric:     {Query: testQuery, RemoveClusterFilter: true},
+			"other_metric": {Query: testQuery, RemoveClusterFilter: true},
+		},
+		NRDBClient:   &testClient{},
+		AccountID:    1,
+		RegisterFunc: metrics.NewKubeRegistry().Register,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating direct provider: %v", err)
+	}
+
+	cacheProvider, err := cacheprovider.NewCacheProvider(cacheprovider.ProviderOptions{
+		ExternalProvider: directProvider,
+		CacheTTLSeconds:  300,
+		RegisterFunc:     metrics.NewKubeRegistry().Register,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating cache provider: %v", err)
+	}
+
+	sl, err := labels.Parse("app=foo")
+	if err != nil {
+		t.Fatalf("Parsing selector: %v", err)
+	}
+
+	ctx := testutil.ContextWithDeadline(t)
+	info := provider.ExternalMetricInfo{Metric: testMetric}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cacheProvider.GetExternalMetric(ctx, "", sl, info); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	return status.Handler(status.Options{
+		DirectProvider: directProvider,
+		CacheProvider:  cacheProvider,
+		HPALister:      testLister(t),
+	})
+}
+
+func testLister(t *testing.T) autoscalingv2listers.HorizontalPodAutoscalerLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-hpa", Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ExternalMetricSourceType,
+					External: &autoscalingv2.ExternalMetricSource{
+						Metric: autoscalingv2.MetricIdentifier{Name: testMetric},
+					},
+				},
+			},
+		},
+	}
+
+	if err := indexer.Add(hpa); err != nil {
+		t.Fatalf("Adding HPA: %v", err)
+	}
+
+	return autoscalingv2listers.NewHorizontalPodAutoscalerLister(indexer)
+}
+
+func serve(t *testing.T, handler http.Handler, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(method, target, nil).WithContext(testutil.ContextWithDeadline(t))
+	response := httptest.NewRecorder()
+
+	handler.ServeHTTP(response, request)
+
+	return response
+}
+
+type testClient struct{}
+
+func (testClient) QueryWithContext(_ context.Context, _ int, _ nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{
+			{
+				"value":     float64(1),
+				"timestamp": float64(time.Now().UnixNano() / 1000000),
+			},
+		},
+	}, nil
+}
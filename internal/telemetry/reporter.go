@@ -0,0 +1,277 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package telemetry implements reporting of the adapter's own metrics to New Relic as events, so the health
+// of the adapter can be monitored without scraping its Prometheus metrics.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+)
+
+const (
+	// DefaultInterval is the time between reports.
+	DefaultInterval = time.Minute
+
+	// EventType is the type of reported events. A single event is reported for each metric.
+	EventType = "NewRelicMetricsAdapterSample"
+
+	// Reported metrics of the adapter.
+	queriesMetric       = "newrelic_adapter_external_provider_queries_total"
+	queryDurationMetric = "newrelic_adapter_external_provider_query_duration_seconds"
+	valueMetric         = "newrelic_adapter_external_provider_value"
+	cacheRequestsMetric = "newrelic_adapter_external_provider_cache_requests_total"
+
+	metricLabel = "metric"
+	resultLabel = "result"
+
+	queryResultOK  = "ok"
+	cacheResultHit = "hit"
+
+	debug = klog.Level(2)
+)
+
+// EventsClient sends events to the New Relic Event API, e.g. the events client of New Relic client.
+type EventsClient interface {
+	CreateEventWithContext(ctx context.Context, accountID int, event interface{}) error
+}
+
+// Options holds the configuration of the reporter.
+type Options struct {
+	Client    EventsClient
+	AccountID int64
+	// Gatherer gathers the reported metrics, usually from the legacy registry.
+	Gatherer metrics.Gatherer
+	// ClusterName and PodName are added to every event. Empty values are omitted.
+	ClusterName string
+	PodName     string
+	// Interval is the time between reports. Defaults to DefaultInterval.
+	Interval time.Duration
+}
+
+// Reporter periodically reports, for each metric, the number of queries and failed queries, average query
+// duration, cache hit ratio and the served value to New Relic.
+//
+// Counts and durations are reported as deltas since the previous report, so they can be summed in NRQL. The
+// served value is reported only for metrics with a single value, i.e. when values are not exported by
+// selector.
+type Reporter struct {
+	options Options
+	// previous holds values of counters at the previous report.
+	previous map[string]float64
+}
+
+// NewReporter is the constructor for the reporter.
+func NewReporter(options Options) (*Reporter, error) {
+	if options.Client == nil {
+		return nil, fmt.Errorf("events client must be configured")
+	}
+
+	if options.AccountID == 0 {
+		return nil, fmt.Errorf("account ID must be configured")
+	}
+
+	if options.Gatherer == nil {
+		return nil, fmt.Errorf("metrics gatherer must be configured")
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = DefaultInterval
+	}
+
+	return &Reporter{
+		options:  options,
+		previous: map[string]float64{},
+	}, nil
+}
+
+// Run reports metrics every interval until the given context is done.
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Report(ctx); err != nil {
+				klog.ErrorS(err, "Reporting adapter telemetry to New Relic failed")
+			}
+		}
+	}
+}
+
+// Report sends events with the current metrics. It must not be called concurrently.
+func (r *Reporter) Report(ctx context.Context) error {
+	families, err := r.options.Gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics: %w", err)
+	}
+
+	events := r.events(families)
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := r.options.Client.CreateEventWithContext(ctx, int(r.options.AccountID), events); err != nil {
+		return fmt.Errorf("sending %d events: %w", len(events), err)
+	}
+
+	klog.V(debug).InfoS("Reported adapter telemetry to New Relic", logkeys.Count, len(events))
+
+	return nil
+}
+
+// sample accumulates attributes of the event of a single metric.
+type sample struct {
+	queries           float64
+	queryErrors       float64
+	queryDurationSum  float64
+	queryDurationSize float64
+	cacheRequests     float64
+	cacheHits         float64
+	values            []float64
+}
+
+// events returns events of each metric in the given families, sorted by metric name.
+func (r *Reporter) events(families []*dto.MetricFamily) []map[string]interface{} {
+	samples := map[string]*sample{}
+
+	sampleOf := func(m *dto.Metric) *sample {
+		name := label(m, metricLabel)
+
+		s, ok := samples[name]
+		if !ok {
+			s = &sample{}
+			samples[name] = s
+		}
+
+		return s
+	}
+
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName() + seriesKey(m)
+
+			switch family.GetName() {
+			case queriesMetric:
+				s := sampleOf(m)
+				delta := r.delta(key, m.GetCounter().GetValue())
+
+				s.queries += delta
+				if label(m, resultLabel) != queryResultOK {
+					s.queryErrors += delta
+				}
+			case queryDurationMetric:
+				s := sampleOf(m)
+				s.queryDurationSum += r.delta(key+"_sum", m.GetHistogram().GetSampleSum())
+				s.queryDurationSize += r.delta(key+"_count", float64(m.GetHistogram().GetSampleCount()))
+			case cacheRequestsMetric:
+				s := sampleOf(m)
+				delta := r.delta(key, m.GetCounter().GetValue())
+
+				s.cacheRequests += delta
+				if label(m, resultLabel) == cacheResultHit {
+					s.cacheHits += delta
+				}
+			case valueMetric:
+				s := sampleOf(m)
+				s.values = append(s.values, m.GetGauge().GetValue())
+			}
+		}
+	}
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	events := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		events = append(events, r.event(name, samples[name]))
+	}
+
+	return events
+}
+
+func (r *Reporter) event(name string, s *sample) map[string]interface{} {
+	event := map[string]interface{}{
+		"eventType":     EventType,
+		"metricName":    name,
+		"queries":       s.queries,
+		"queryErrors":   s.queryErrors,
+		"cacheRequests": s.cacheRequests,
+		"cacheHits":     s.cacheHits,
+	}
+
+	if r.options.ClusterName != "" {
+		event["clusterName"] = r.options.ClusterName
+	}
+
+	if r.options.PodName != "" {
+		event["podName"] = r.options.PodName
+	}
+
+	if s.queryDurationSize > 0 {
+		event["averageQueryDurationSeconds"] = s.queryDurationSum / s.queryDurationSize
+	}
+
+	if s.cacheRequests > 0 {
+		event["cacheHitRatio"] = s.cacheHits / s.cacheRequests
+	}
+
+	if len(s.values) == 1 {
+		event["value"] = s.values[0]
+	}
+
+	return event
+}
+
+// delta returns the increase of the counter of the given key since the previous report.
+func (r *Reporter) delta(key string, value float64) float64 {
+	previous := r.previous[key]
+	r.previous[key] = value
+
+	// Counters of a single process never decrease, but be safe if one gets recreated.
+	if value < previous {
+		return value
+	}
+
+	return value - previous
+}
+
+// seriesKey returns key identifying the series of the given metric within its family.
+func seriesKey(m *dto.Metric) string {
+	pairs := make([]string, 0, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", l.GetName(), l.GetValue()))
+	}
+
+	// Labels are sorted by the registry, but do not rely on it.
+	sort.Strings(pairs)
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func label(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 New Relic Corporation. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetry_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	nrConfig "github.com/newrelic/newrelic-client-go/v2/pkg/config"
+	"github.com/newrelic/newrelic-client-go/v2/pkg/events"
+	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
+	"k8s.io/component-base/metrics"
+	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/telemetry"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/testutil"
+)
+
+//nolint:gochecknoglobals // Shared by all tests.
+var testInfo = provider.ExternalMetricInfo{Metric: testMetric}
+
+const (
+	testMetric     = "test_metric"
+	testAccountID  = 1
+	testLicenseKey = "test-license-key"
+)
+
+//nolint:funlen,cyclop // Just a large test suite.
+func Test_Reporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sends_event_of_each_metric_with_cluster_name_to_Event_API", func(t *testing.T) {
+		t.Parallel()
+
+		server := &eventAPI{}
+		reporter, cacheProvider := testReporter(t, server)
+
+		ctx := testutil.ContextWithDeadline(t)
+
+		for i := 0; i < 2; i++ {
+			if _, err := cacheProvider.GetExternalMetric(ctx, "", nil, testInfo); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if err := reporter.Report(ctx); err != nil {
+			t.Fatalf("Unexpected error reporting: %v", err)
+		}
+
+		if server.licenseKey != testLicenseKey {
+			t.Errorf("Expected license key %q, got %q", testLicenseKey, server.licenseKey)
+		}
+
+		event := server.event(t, testMetric)
+
+		for attribute, expected := range map[string]interface{}{
+			"eventType":     telemetry.EventType,
+			"clusterName":   "test-cluster",
+			"queries":       float64(1),
+			"queryErrors":   float64(0),
+			"cacheRequests": float64(2),
+			"cacheHits":     float64(1),
+			"cacheHitRatio": 0.5,
+			"value":         float64(1),
+		} {
+			if event[attribute] != expected {
+				t.Errorf("Expected attribute %q to be %v, got %v", attribute, expected, event[attribute])
+			}
+		}
+
+		if _, ok := event["averageQueryDurationSeconds"]; !ok {
+			t.Errorf("Expected average query duration, got %v", event)
+		}
+	})
+
+	t.Run("reports_counts_since_previous_report", func(t *testing.T) {
+		t.Parallel()
+
+		server := &eventAPI{}
+		reporter, cacheProvider := testReporter(t, server)
+
+		ctx := testutil.ContextWithDeadline(t)
+
+		if _, err := cacheProvider.GetExternalMetric(ctx, "", nil, testInfo); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := reporter.Report(ctx); err != nil {
+				t.Fatalf("Unexpected error reporting: %v", err)
+			}
+		}
+
+		event := server.event(t, testMetric)
+
+		if event["queries"] != float64(0) || event["cacheRequests"] != float64(0) {
+			t.Errorf("Expected no queries and cache requests since previous report, got %v", event)
+		}
+
+		for _, attribute := range []string{"cacheHitRatio", "averageQueryDurationSeconds"} {
+			if _, ok := event[attribute]; ok {
+				t.Errorf("Expected no attribute %q without requests, got %v", attribute, event)
+			}
+		}
+	})
+
+	t.Run("returns_error_when_Event_API_rejects_events", func(t *testing.T) {
+		t.Parallel()
+
+		server := &eventAPI{status: http.StatusForbidden}
+		reporter, cacheProvider := testReporter(t, server)
+
+		ctx := testutil.ContextWithDeadline(t)
+
+		if _, err := cacheProvider.GetExternalMetric(ctx, "", nil, testInfo); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := reporter.Report(ctx); err == nil {
+			t.Fatalf("Expected error")
+		}
+	})
+}
+
+func Test_Creating_reporter_fails_when(t *testing.T) {
+	t.Parallel()
+
+	for name, options := range map[string]telemetry.Options{
+		"no_client_is_configured": {
+			AccountID: testAccountID,
+			Gatherer:  metrics.NewKubeRegistry(),
+		},
+		"no_account_ID_is_configured": {
+			Client:   &events.Events{},
+			Gatherer: metrics.NewKubeRegistry(),
+		},
+		"no_gatherer_is_configured": {
+			Client:    &events.Events{},
+			AccountID: testAccountID,
+		},
+	} {
+		options := options
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := telemetry.NewReporter(options); err == nil {
+				t.Fatalf("Expected error")
+			}
+		})
+	}
+}
+
+// testReporter returns reporter sending events to the given stand-in of the Event API, together with
+// a cache provider which metrics are reported.
+func testReporter(t *testing.T, server *eventAPI) (*telemetry.Reporter, provider.ExternalMetricsProvider) {
+	t.Helper()
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	registry := metrics.NewKubeRegistry()
+
+	directProvider, err := newrelic.NewDirectProvider(newrelic.ProviderOptions{
+		ExternalMetrics: map[string]newrelic.Metric{
+			testMetric: {Query: "select test from testSample"},
+		},
+		NRDBClient:   &testClient{},
+		AccountID:    testAccountID,
+		ClusterName:  "test-cluster",
+		RegisterFunc: registry.Register,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating direct provider: %v", err)
+	}
+
+	cacheProvider, err := cache.NewCacheProvider(cache.ProviderOptions{
+		ExternalProvider: directProvider,
+		CacheTTLSeconds:  300,
+		RegisterFunc:     registry.Register,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating cache provider: %v", err)
+	}
+
+	cfg := nrConfig.New()
+	cfg.LicenseKey = testLicenseKey
+	cfg.Region().SetInsightsBaseURL(httpServer.URL)
+
+	client := events.New(cfg)
+
+	reporter, err := telemetry.NewReporter(telemetry.Options{
+		Client:      &client,
+		AccountID:   testAccountID,
+		Gatherer:    registry,
+		ClusterName: "test-cluster",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating reporter: %v", err)
+	}
+
+	return reporter, cacheProvider
+}
+
+// eventAPI is a local stand-in of the New Relic Event API recording the latest received events.
+type eventAPI struct {
+	// status is the status code of responses. Defaults to 200.
+	status int
+
+	mu         sync.Mutex
+	licenseKey string
+	events     []map[string]interface{}
+}
+
+func (e *eventAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.licenseKey = r.Header.Get("X-License-Key")
+
+	if e.status != 0 {
+		w.WriteHeader(e.status)
+
+		return
+	}
+
+	body, err := gzip.NewReader(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	e.events = nil
+
+	if err := json.NewDecoder(body).Decode(&e.events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	_, _ = w.Write([]byte(`{"success": true, "uuid": "test"}`))
+}
+
+// event returns the latest received event of the given metric.
+func (e *eventAPI) event(t *testing.T, metric string) map[string]interface{} {
+	t.Helper()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, event := range e.events {
+		if event["metricName"] == metric {
+			return event
+		}
+	}
+
+	t.Fatalf("No event of metric %q received, got %v", metric, e.events)
+
+	return nil
+}
+
+type testClient struct{}
+
+func (testClient) QueryWithContext(_ context.Context, _ int, _ nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{
+			{
+				"value":     float64(1),
+				"timestamp": float64(time.Now().UnixNano() / 1000000),
+			},
+		},
+	}, nil
+}
@@ -7,23 +7,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
 	nrClient "github.com/newrelic/newrelic-client-go/v2/newrelic"
+	nrConfig "github.com/newrelic/newrelic-client-go/v2/pkg/config"
 	"github.com/newrelic/newrelic-client-go/v2/pkg/nrdb"
 	"github.com/newrelic/newrelic-client-go/v2/pkg/region"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	logsjson "k8s.io/component-base/logs/json"
 	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/tracing"
+	tracingv1 "k8s.io/component-base/tracing/api/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	basecmd "sigs.k8s.io/custom-metrics-apiserver/pkg/cmd"
 	"sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
 	"sigs.k8s.io/yaml"
 
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/adapter"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/logkeys"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/audit"
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/cache"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/events"
 	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/provider/newrelic"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/status"
+	"github.com/newrelic/newrelic-k8s-metrics-adapter/internal/telemetry"
 )
 
 const (
@@ -34,20 +59,161 @@ const (
 	// adapter to run.
 	NewRelicAPIKeyEnv = "NEWRELIC_API_KEY"
 
+	// NewRelicLicenseKeyEnv is an environment variable name which must be set with a valid NewRelic license key
+	// when telemetry reporter is enabled, as the Event API does not accept the personal API key.
+	NewRelicLicenseKeyEnv = "NEWRELIC_LICENSE_KEY"
+
 	// ClusterNameEnv is an environment variable name which will be read for filtering cluster-scoped metrics.
 	ClusterNameEnv = "CLUSTER_NAME"
 
 	// NrdbClientMaxTimeoutSeconds is the maximum timeout that could be set to the nrdb client.
 	NrdbClientMaxTimeoutSeconds = 120
+
+	// PodNamespaceEnv is an environment variable name which will be read to get the namespace adapter is running in.
+	PodNamespaceEnv = "POD_NAMESPACE"
+
+	// PodNameEnv is an environment variable name which will be read to identify the adapter replica.
+	PodNameEnv = "POD_NAME"
+
+	// CacheStorageMemory keeps cached values in memory of each adapter replica.
+	CacheStorageMemory = "memory"
+
+	// CacheStorageConfigMap shares cached values between adapter replicas using a ConfigMap.
+	CacheStorageConfigMap = "configMap"
+
+	// LoggingFormatText logs in klog text format.
+	LoggingFormatText = "text"
+
+	// LoggingFormatJSON logs structured entries as JSON objects, one per line.
+	LoggingFormatJSON = "json"
 )
 
 // ConfigOptions represents supported configuration options for metric-adapter.
 type ConfigOptions struct {
-	AccountID                int64                      `json:"accountID"`
-	ExternalMetrics          map[string]newrelic.Metric `json:"externalMetrics"`
-	Region                   string                     `json:"region"`
-	CacheTTLSeconds          int64                      `json:"cacheTTLSeconds"`
-	NrdbClientTimeoutSeconds int                        `json:"nrdbClientTimeoutSeconds"`
+	AccountID                   int64                      `json:"accountID"`
+	ExternalMetrics             map[string]newrelic.Metric `json:"externalMetrics"`
+	Region                      string                     `json:"region"`
+	CacheTTLSeconds             int64                      `json:"cacheTTLSeconds"`
+	CacheTTLBasis               cache.TTLBasis             `json:"cacheTTLBasis"`
+	CacheStorage                CacheStorageOptions        `json:"cacheStorage"`
+	CacheErrorBackoffSeconds    int64                      `json:"cacheErrorBackoffSeconds"`
+	CacheMaxErrorBackoffSeconds int64                      `json:"cacheMaxErrorBackoffSeconds"`
+	CacheWarmUp                 CacheWarmUpOptions         `json:"cacheWarmUp"`
+	NrdbClientTimeoutSeconds    int                        `json:"nrdbClientTimeoutSeconds"`
+	NrdbRetry                   NrdbRetryOptions           `json:"nrdbRetry"`
+	NrdbCircuitBreaker          NrdbCircuitBreakerOptions  `json:"nrdbCircuitBreaker"`
+	NrdbRateLimit               NrdbRateLimitOptions       `json:"nrdbRateLimit"`
+	NrdbMaxConcurrentQueries    int                        `json:"nrdbMaxConcurrentQueries"`
+	NrdbThrottle                NrdbThrottleOptions        `json:"nrdbThrottle"`
+	NrdbHedging                 NrdbHedgingOptions         `json:"nrdbHedging"`
+	ValueMetrics                ValueMetricsOptions        `json:"valueMetrics"`
+	Tracing                     *TracingOptions            `json:"tracing"`
+	AuditLog                    *AuditLogOptions           `json:"auditLog"`
+	HPAEvents                   HPAEventsOptions           `json:"hpaEvents"`
+	HealthChecks                HealthChecksOptions        `json:"healthChecks"`
+	TelemetryReporter           TelemetryReporterOptions   `json:"telemetryReporter"`
+	QueryCost                   QueryCostOptions           `json:"queryCost"`
+}
+
+// NrdbRetryOptions represents configuration of retrying NRDB queries failing with transient errors.
+type NrdbRetryOptions struct {
+	MaxRetries                 int   `json:"maxRetries"`
+	InitialBackoffMilliseconds int64 `json:"initialBackoffMilliseconds"`
+	MaxBackoffMilliseconds     int64 `json:"maxBackoffMilliseconds"`
+}
+
+// CacheStorageOptions represents configuration of the storage used for caching external metric values.
+type CacheStorageOptions struct {
+	Type               string `json:"type"`
+	ConfigMapName      string `json:"configMapName"`
+	LockTTLSeconds     int64  `json:"lockTTLSeconds"`
+	MaxEntryAgeSeconds int64  `json:"maxEntryAgeSeconds"`
+}
+
+// CacheWarmUpOptions represents configuration of filling the cache before the adapter reports being ready.
+type CacheWarmUpOptions struct {
+	Enabled        bool   `json:"enabled"`
+	SelectorsFile  string `json:"selectorsFile"`
+	TimeoutSeconds int64  `json:"timeoutSeconds"`
+}
+
+// NrdbCircuitBreakerOptions represents configuration of failing NRDB queries fast while they keep failing.
+type NrdbCircuitBreakerOptions struct {
+	FailureThreshold    int   `json:"failureThreshold"`
+	OpenDurationSeconds int64 `json:"openDurationSeconds"`
+}
+
+// NrdbRateLimitOptions represents configuration of limiting the rate of all NRDB queries.
+type NrdbRateLimitOptions struct {
+	newrelic.RateLimit
+	// Wait makes queries over the limit wait for the budget instead of being rejected.
+	Wait bool `json:"wait"`
+}
+
+// NrdbThrottleOptions represents configuration of throttling NRDB queries rate limited by NerdGraph.
+type NrdbThrottleOptions struct {
+	Enabled            bool  `json:"enabled"`
+	MaxIntervalSeconds int64 `json:"maxIntervalSeconds"`
+}
+
+// NrdbHedgingOptions represents configuration of hedging NRDB queries which have not returned in time.
+type NrdbHedgingOptions struct {
+	DelayMilliseconds int64   `json:"delayMilliseconds"`
+	LatencyPercentile float64 `json:"latencyPercentile"`
+}
+
+// ValueMetricsOptions represents configuration of exporting the latest served values of external metrics.
+type ValueMetricsOptions struct {
+	BySelector   bool `json:"bySelector"`
+	MaxSelectors int  `json:"maxSelectors"`
+}
+
+// TracingOptions represents configuration of exporting OpenTelemetry spans of requests via OTLP. It extends
+// the tracing configuration of Kubernetes components.
+type TracingOptions struct {
+	tracingv1.TracingConfiguration
+	// RedactQueries replaces literals of NRQL queries recorded in spans with placeholders and omits selectors
+	// and error messages from spans.
+	RedactQueries bool `json:"redactQueries"`
+}
+
+// AuditLogOptions represents configuration of recording requests for external metrics.
+type AuditLogOptions struct {
+	// Path of the audit log file, or "-" for the standard output.
+	Path             string `json:"path"`
+	MaxSizeMegabytes int    `json:"maxSizeMegabytes"`
+	MaxBackups       int    `json:"maxBackups"`
+	MaxAgeDays       int    `json:"maxAgeDays"`
+	Compress         bool   `json:"compress"`
+}
+
+// HPAEventsOptions represents configuration of emitting Events on HPAs referencing failing external metrics.
+type HPAEventsOptions struct {
+	Enabled         bool  `json:"enabled"`
+	IntervalSeconds int64 `json:"intervalSeconds"`
+}
+
+// HealthChecksOptions represents configuration of readiness checks reporting health of New Relic queries.
+type HealthChecksOptions struct {
+	Disabled             bool  `json:"disabled"`
+	MaxQueryAgeSeconds   int64 `json:"maxQueryAgeSeconds"`
+	FailOnRejectedAPIKey bool  `json:"failOnRejectedAPIKey"`
+	FailOnOutage         bool  `json:"failOnOutage"`
+	FailOnMetricErrors   bool  `json:"failOnMetricErrors"`
+}
+
+// TelemetryReporterOptions represents configuration of reporting telemetry of the adapter to New Relic as events.
+type TelemetryReporterOptions struct {
+	Enabled         bool  `json:"enabled"`
+	IntervalSeconds int64 `json:"intervalSeconds"`
+}
+
+// QueryCostOptions represents configuration of accounting the cost of NRDB queries.
+type QueryCostOptions struct {
+	// PerformanceStats requests the raw response of NRDB with every query to account the number of inspected
+	// events and the wall-clock time of queries.
+	PerformanceStats      bool  `json:"performanceStats"`
+	EstimateWindowSeconds int64 `json:"estimateWindowSeconds"`
 }
 
 // Run reads configuration file and environment variables to configure and run the adapter.
@@ -55,8 +221,13 @@ func Run(ctx context.Context, args []string) error {
 	flagSet := pflag.NewFlagSet(adapter.Name, pflag.ContinueOnError)
 
 	configPath := flagSet.String("config-file", DefaultConfigPath, "Path to read config file from")
+	loggingFormat := flagSet.String("logging-format", LoggingFormatText,
+		fmt.Sprintf("Sets the log format. Permitted formats: %q, %q", LoggingFormatText, LoggingFormatJSON))
+
+	// Flags are parsed into separate adapter base to be able to create Kubernetes clients before the adapter itself.
+	adapterBase := &basecmd.AdapterBase{}
 
-	err := adapter.ParseFlags(args, flagSet, nil)
+	err := adapter.ParseFlags(args, flagSet, adapterBase)
 	if err != nil {
 		if errors.Is(err, pflag.ErrHelp) {
 			return nil
@@ -65,6 +236,12 @@ func Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("parsing given flags: %w", err)
 	}
 
+	if err := configureLogging(*loggingFormat, adapterBase.FlagSet); err != nil {
+		return fmt.Errorf("configuring logging: %w", err)
+	}
+
+	klog.InfoS("Starting NewRelic metrics adapter")
+
 	config, err := loadConfiguration(*configPath)
 	if err != nil {
 		return fmt.Errorf("loading configuration: %w", err)
@@ -85,21 +262,108 @@ func Run(ctx context.Context, args []string) error {
 		nrClient.ConfigHTTPTimeout(time.Duration(config.NrdbClientTimeoutSeconds) * time.Second),
 	}
 
+	// Created before the transport throttling NerdGraph queries is configured, so responses of the Event API
+	// do not affect the throttling.
+	reporter, err := telemetryReporter(config.TelemetryReporter, config.AccountID, clientOptions)
+	if err != nil {
+		return fmt.Errorf("creating telemetry reporter: %w", err)
+	}
+
+	tracerProvider, err := newTracerProvider(ctx, config.Tracing)
+	if err != nil {
+		return fmt.Errorf("creating tracer provider: %w", err)
+	}
+
+	defer func() {
+		if err := tracerProvider.Shutdown(context.Background()); err != nil {
+			klog.ErrorS(err, "Shutting down tracer provider")
+		}
+	}()
+
+	var transport http.RoundTripper
+
+	throttle := nrdbThrottle(config.NrdbThrottle)
+	if throttle != nil {
+		transport = throttle.Transport(nil)
+	}
+
+	// Propagates the trace context to NerdGraph and records spans of HTTP requests sent to it.
+	if config.Tracing != nil {
+		transport = tracing.WrapperFor(tracerProvider)(transport)
+	}
+
+	if transport != nil {
+		clientOptions = append(clientOptions, nrClient.ConfigHTTPTransport(transport))
+	}
+
 	// The NEWRELIC_API_KEY is read from an envVar populated thanks to a k8s secret.
 	c, err := nrClient.New(clientOptions...)
 	if err != nil {
 		return fmt.Errorf("creating NewRelic client: %w", err)
 	}
 
-	externalMetricsProvider, err := externalMetricsProvider(config, &c.Nrdb)
+	externalMetricsProvider, directProvider, err := externalMetricsProvider(
+		ctx, config, &c.Nrdb, throttle, tracerProvider, adapterBase.ClientConfig,
+	)
 	if err != nil {
 		return fmt.Errorf("creating external metrics provider: %w", err)
 	}
 
+	// Like auditing, Events are emitted only for requests served by the adapter, as warm-up requests
+	// are not made on behalf of any HPA.
+	// HPAs are watched regardless of whether Events are enabled, as the status page lists HPAs using the metrics.
+	hpaClient, hpaLister, err := hpaInformer(ctx, adapterBase.ClientConfig)
+	if err != nil {
+		return fmt.Errorf("creating HPA informer: %w", err)
+	}
+
+	servedProvider, shutdownEvents, err := hpaEventsProvider(
+		ctx, config.HPAEvents, externalMetricsProvider, hpaClient, hpaLister,
+	)
+	if err != nil {
+		return fmt.Errorf("creating HPA events provider: %w", err)
+	}
+
+	defer shutdownEvents()
+
+	// Only requests served by the adapter are audited, so warm-up queries are not recorded.
+	auditedProvider, closeAuditLog, err := auditProvider(config.AuditLog, servedProvider)
+	if err != nil {
+		return fmt.Errorf("creating audit provider: %w", err)
+	}
+
+	defer closeAuditLog()
+
 	options := adapter.Options{
 		Args:                    args,
 		ExtraFlags:              flagSet,
-		ExternalMetricsProvider: externalMetricsProvider,
+		ExternalMetricsProvider: auditedProvider,
+		Handlers:                map[string]http.Handler{},
+	}
+
+	if config.Tracing != nil {
+		options.TracerProvider = tracerProvider
+	}
+
+	// Checks are added to /readyz only, as checks added to /healthz are added to /livez as well and restarting
+	// the adapter does not help when New Relic queries fail.
+	options.ReadyzChecks = append(options.ReadyzChecks, healthChecks(config.HealthChecks, directProvider)...)
+
+	if handler := cache.InspectionHandler(externalMetricsProvider); handler != nil {
+		options.Handlers[cache.InspectionPath] = handler
+	}
+
+	if handler := status.Handler(status.Options{
+		DirectProvider: directProvider,
+		CacheProvider:  externalMetricsProvider,
+		HPALister:      hpaLister,
+	}); handler != nil {
+		options.Handlers[status.Path] = handler
+	}
+
+	warmUp := cacheWarmUp(config.CacheWarmUp, externalMetricsProvider)
+	if warmUp != nil {
+		options.ReadyzChecks = append(options.ReadyzChecks, warmUp)
 	}
 
 	a, err := adapter.NewAdapter(options)
@@ -107,45 +371,409 @@ func Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("initializing adapter: %w", err)
 	}
 
+	if warmUp != nil {
+		go warmUp.Run(ctx)
+	}
+
+	if reporter != nil {
+		go reporter.Run(ctx)
+	}
+
 	return a.Run(ctx) //nolint:wrapcheck // Don't wrap as otherwise error annotations will be duplicated.
 }
 
-func externalMetricsProvider(config *ConfigOptions, nrdb *nrdb.Nrdb) (provider.ExternalMetricsProvider, error) {
+// configureLogging makes klog log in the given format. Verbosity configured by klog flags is preserved.
+func configureLogging(format string, flags *pflag.FlagSet) error {
+	switch format {
+	case LoggingFormatText:
+		return nil
+	case LoggingFormatJSON:
+	default:
+		return fmt.Errorf("unsupported logging format %q, expected %q or %q", format, LoggingFormatText, LoggingFormatJSON)
+	}
+
+	config := logsapi.NewLoggingConfiguration()
+	config.Format = format
+
+	if err := logsapi.VerbosityLevelPflag(&config.Verbosity).Set(flags.Lookup("v").Value.String()); err != nil {
+		return fmt.Errorf("parsing verbosity: %w", err)
+	}
+
+	logger, control := logsjson.Factory{}.Create(*config, logsapi.LoggingOptions{
+		ErrorStream: os.Stderr,
+		InfoStream:  os.Stdout,
+	})
+
+	klog.SetLoggerWithOptions(logger, klog.ContextualLogger(true), klog.FlushLogger(control.Flush))
+
+	return nil
+}
+
+// newTracerProvider returns tracer provider exporting spans via OTLP, or a provider not recording any spans
+// when tracing is not configured.
+func newTracerProvider(ctx context.Context, options *TracingOptions) (tracing.TracerProvider, error) {
+	if options == nil {
+		return tracing.NewNoopTracerProvider(), nil
+	}
+
+	if errs := tracingv1.ValidateTracingConfiguration(
+		&options.TracingConfiguration, nil, field.NewPath("tracing"),
+	); len(errs) > 0 {
+		return nil, fmt.Errorf("validating tracing configuration: %w", errs.ToAggregate())
+	}
+
+	klog.InfoS("Spans of requests will be exported via OTLP")
+
+	resourceOptions := []resource.Option{
+		resource.WithAttributes(semconv.ServiceName(adapter.Name)),
+	}
+
+	tracerProvider, err := tracing.NewProvider(ctx, &options.TracingConfiguration, nil, resourceOptions)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	return tracerProvider, nil
+}
+
+// auditProvider returns provider recording requests served by the given provider to the configured audit
+// log, together with a function closing the log. When audit log is not configured, the given provider is
+// returned.
+func auditProvider(
+	options *AuditLogOptions, p provider.ExternalMetricsProvider,
+) (provider.ExternalMetricsProvider, func(), error) {
+	if options == nil {
+		return p, func() {}, nil
+	}
+
+	writer, err := audit.NewWriter(audit.WriterOptions{
+		Path:             options.Path,
+		MaxSizeMegabytes: options.MaxSizeMegabytes,
+		MaxBackups:       options.MaxBackups,
+		MaxAgeDays:       options.MaxAgeDays,
+		Compress:         options.Compress,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating audit log writer: %w", err)
+	}
+
+	closeWriter := func() {
+		if err := writer.Close(); err != nil {
+			klog.ErrorS(err, "Closing audit log")
+		}
+	}
+
+	auditedProvider, err := audit.NewAuditProvider(audit.ProviderOptions{
+		ExternalProvider: p,
+		Writer:           writer,
+	})
+	if err != nil {
+		closeWriter()
+
+		return nil, nil, fmt.Errorf("creating provider: %w", err)
+	}
+
+	klog.InfoS("Requests for external metrics will be recorded to audit log", logkeys.File, options.Path)
+
+	return auditedProvider, closeWriter, nil
+}
+
+// hpaInformer returns client of the Kubernetes API and lister of HPAs in all namespaces. HPAs are listed from
+// a shared informer cache running until the given context is done.
+func hpaInformer(
+	ctx context.Context, clientConfig func() (*rest.Config, error),
+) (kubernetes.Interface, autoscalingv2listers.HorizontalPodAutoscalerLister, error) {
+	restConfig, err := clientConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting Kubernetes client config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating Kubernetes client: %w", err)
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(clientset, 0)
+	// Lister must be requested before the factory is started, so the informer is started as well.
+	lister := informerFactory.Autoscaling().V2().HorizontalPodAutoscalers().Lister()
+
+	informerFactory.Start(ctx.Done())
+
+	return clientset, lister, nil
+}
+
+// hpaEventsProvider returns provider emitting Events on HPAs referencing external metrics the given provider
+// failed to get, together with a function stopping emitting Events. Events are created using the given client
+// on HPAs listed by the given lister. When Events are not enabled, the given provider is returned.
+func hpaEventsProvider(
+	ctx context.Context,
+	options HPAEventsOptions,
+	p provider.ExternalMetricsProvider,
+	client kubernetes.Interface,
+	lister autoscalingv2listers.HorizontalPodAutoscalerLister,
+) (provider.ExternalMetricsProvider, func(), error) {
+	if !options.Enabled {
+		return p, func() {}, nil
+	}
+
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartRecordingToSink(&corev1client.EventSinkImpl{Interface: client.CoreV1().Events("")})
+
+	eventsProvider, err := events.NewEventsProvider(events.ProviderOptions{
+		ExternalProvider: p,
+		Lister:           lister,
+		Recorder:         broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: adapter.Name}),
+		Interval:         time.Duration(options.IntervalSeconds) * time.Second,
+	})
+	if err != nil {
+		broadcaster.Shutdown()
+
+		return nil, nil, fmt.Errorf("creating provider: %w", err)
+	}
+
+	klog.InfoS("Events will be emitted on HPAs referencing failing external metrics")
+
+	return eventsProvider, broadcaster.Shutdown, nil
+}
+
+// telemetryReporter returns reporter sending telemetry of the adapter to New Relic, unless disabled. Events
+// are sent by a client configured with the given options and the license key.
+func telemetryReporter(
+	options TelemetryReporterOptions, accountID int64, clientOptions []nrClient.ConfigOption,
+) (*telemetry.Reporter, error) {
+	if !options.Enabled {
+		return nil, nil
+	}
+
+	licenseKey := os.Getenv(NewRelicLicenseKeyEnv)
+	if licenseKey == "" {
+		return nil, fmt.Errorf("%s must be set to report telemetry", NewRelicLicenseKeyEnv)
+	}
+
+	clientOptions = append(clientOptions[:len(clientOptions):len(clientOptions)], configLicenseKey(licenseKey))
+
+	c, err := nrClient.New(clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("creating NewRelic client: %w", err)
+	}
+
+	reporter, err := telemetry.NewReporter(telemetry.Options{
+		Client:      &c.Events,
+		AccountID:   accountID,
+		Gatherer:    legacyregistry.DefaultGatherer,
+		ClusterName: os.Getenv(ClusterNameEnv),
+		PodName:     os.Getenv(PodNameEnv),
+		Interval:    time.Duration(options.IntervalSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating reporter: %w", err)
+	}
+
+	klog.InfoS("Telemetry of the adapter will be reported to New Relic", logkeys.EventType,
+		telemetry.EventType)
+
+	return reporter, nil
+}
+
+// configLicenseKey sets the license key authenticating requests to the Event API, which NewRelic client
+// has no option for.
+func configLicenseKey(licenseKey string) nrClient.ConfigOption {
+	return func(cfg *nrConfig.Config) error {
+		cfg.LicenseKey = licenseKey
+
+		return nil
+	}
+}
+
+// nrdbThrottle returns throttle adapting the rate of queries to NerdGraph rate limiting, if enabled.
+func nrdbThrottle(options NrdbThrottleOptions) *newrelic.Throttle {
+	if !options.Enabled {
+		return nil
+	}
+
+	klog.InfoS("Queries will be throttled when NerdGraph rate limits them")
+
+	return newrelic.NewThrottle(newrelic.ThrottleOptions{
+		MaxInterval: time.Duration(options.MaxIntervalSeconds) * time.Second,
+	})
+}
+
+// maxQueryTimeout returns the maximum timeout a metric may configure, which is the timeout of the NRDB client,
+// as a longer one would never be reached by a query.
+func maxQueryTimeout(clientTimeoutSeconds int) time.Duration {
+	if clientTimeoutSeconds <= 0 {
+		return NrdbClientMaxTimeoutSeconds * time.Second
+	}
+
+	return time.Duration(clientTimeoutSeconds) * time.Second
+}
+
+// externalMetricsProvider returns the provider serving external metrics, together with the direct provider
+// executing their queries, which is encapsulated by it.
+func externalMetricsProvider(
+	ctx context.Context,
+	config *ConfigOptions,
+	nrdb *nrdb.Nrdb,
+	throttle *newrelic.Throttle,
+	tracerProvider trace.TracerProvider,
+	clientConfig func() (*rest.Config, error),
+) (provider.ExternalMetricsProvider, provider.ExternalMetricsProvider, error) {
+	nrdbClient := newrelic.NRDBClient(nrdb)
+	if config.QueryCost.PerformanceStats {
+		klog.InfoS("Performance statistics of queries will be requested from NRDB")
+
+		nrdbClient = newrelic.WithPerformanceStats(nrdb)
+	}
+
 	providerOptions := newrelic.ProviderOptions{
 		ExternalMetrics: config.ExternalMetrics,
-		NRDBClient:      nrdb,
+		NRDBClient:      nrdbClient,
 		AccountID:       config.AccountID,
 		ClusterName:     os.Getenv(ClusterNameEnv),
-		RegisterFunc:    legacyregistry.Register,
+		Retry: newrelic.RetryOptions{
+			MaxRetries:     config.NrdbRetry.MaxRetries,
+			InitialBackoff: time.Duration(config.NrdbRetry.InitialBackoffMilliseconds) * time.Millisecond,
+			MaxBackoff:     time.Duration(config.NrdbRetry.MaxBackoffMilliseconds) * time.Millisecond,
+		},
+		CircuitBreaker: newrelic.CircuitBreakerOptions{
+			FailureThreshold: config.NrdbCircuitBreaker.FailureThreshold,
+			OpenDuration:     time.Duration(config.NrdbCircuitBreaker.OpenDurationSeconds) * time.Second,
+		},
+		RateLimit:        config.NrdbRateLimit.RateLimit,
+		WaitForRateLimit: config.NrdbRateLimit.Wait,
+		Hedge: newrelic.HedgeOptions{
+			Delay:             time.Duration(config.NrdbHedging.DelayMilliseconds) * time.Millisecond,
+			LatencyPercentile: config.NrdbHedging.LatencyPercentile,
+		},
+		MaxConcurrentQueries: config.NrdbMaxConcurrentQueries,
+		MaxQueryTimeout:      maxQueryTimeout(config.NrdbClientTimeoutSeconds),
+		Throttle:             throttle,
+		ValueMetrics: newrelic.ValueMetricsOptions{
+			BySelector:   config.ValueMetrics.BySelector,
+			MaxSelectors: config.ValueMetrics.MaxSelectors,
+		},
+		Cost: newrelic.CostOptions{
+			EstimateWindow: time.Duration(config.QueryCost.EstimateWindowSeconds) * time.Second,
+		},
+		TracerProvider:      tracerProvider,
+		RedactTracedQueries: config.Tracing != nil && config.Tracing.RedactQueries,
+		RegisterFunc:        legacyregistry.Register,
 	}
 
 	directProvider, err := newrelic.NewDirectProvider(providerOptions)
 	if err != nil {
-		return nil, fmt.Errorf("creating direct provider: %w", err)
+		return nil, nil, fmt.Errorf("creating direct provider: %w", err)
+	}
+
+	storage, err := cacheStorage(ctx, config.CacheStorage, clientConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating cache storage: %w", err)
 	}
 
 	cacheOptions := cache.ProviderOptions{
-		ExternalProvider: directProvider,
-		CacheTTLSeconds:  config.CacheTTLSeconds,
-		RegisterFunc:     legacyregistry.Register,
+		ExternalProvider:       directProvider,
+		CacheTTLSeconds:        config.CacheTTLSeconds,
+		TTLBasis:               config.CacheTTLBasis,
+		Storage:                storage,
+		ErrorBackoffSeconds:    config.CacheErrorBackoffSeconds,
+		MaxErrorBackoffSeconds: config.CacheMaxErrorBackoffSeconds,
+		RefreshWait:            time.Duration(config.CacheStorage.LockTTLSeconds) * time.Second,
+		TracerProvider:         tracerProvider,
+		RedactTraces:           config.Tracing != nil && config.Tracing.RedactQueries,
+		RegisterFunc:           legacyregistry.Register,
 	}
 
 	cacheProvider, err := cache.NewCacheProvider(cacheOptions)
 	if err != nil {
-		return nil, fmt.Errorf("creating cache provider: %w", err)
+		return nil, nil, fmt.Errorf("creating cache provider: %w", err)
+	}
+
+	return cacheProvider, directProvider, nil
+}
+
+// healthChecks returns checks reporting health of the configuration and queries of the given provider, unless disabled.
+func healthChecks(options HealthChecksOptions, p provider.ExternalMetricsProvider) []healthz.HealthChecker {
+	if options.Disabled {
+		klog.InfoS("Health checks of configuration and New Relic queries are disabled")
+
+		return nil
+	}
+
+	return newrelic.HealthChecks(p, newrelic.HealthOptions{
+		MaxQueryAge:          time.Duration(options.MaxQueryAgeSeconds) * time.Second,
+		FailOnRejectedAPIKey: options.FailOnRejectedAPIKey,
+		FailOnOutage:         options.FailOnOutage,
+		FailOnMetricErrors:   options.FailOnMetricErrors,
+	})
+}
+
+func cacheWarmUp(options CacheWarmUpOptions, p provider.ExternalMetricsProvider) *cache.WarmUp {
+	if !options.Enabled {
+		return nil
+	}
+
+	warmUp := cache.NewWarmUp(p, cache.WarmUpOptions{
+		SelectorsFile: options.SelectorsFile,
+		Timeout:       time.Duration(options.TimeoutSeconds) * time.Second,
+	})
+	if warmUp == nil {
+		klog.InfoS("Cache warm-up is enabled, but cache is disabled. Skipping warm-up.")
+	}
+
+	return warmUp
+}
+
+func cacheStorage(
+	ctx context.Context, options CacheStorageOptions, clientConfig func() (*rest.Config, error),
+) (cache.Storage, error) {
+	switch options.Type {
+	case "", CacheStorageMemory:
+		return cache.NewMemoryStorage(), nil
+	case CacheStorageConfigMap:
+	default:
+		return nil, fmt.Errorf("unsupported cache storage type %q, expected %q or %q",
+			options.Type, CacheStorageMemory, CacheStorageConfigMap)
+	}
+
+	restConfig, err := clientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("getting Kubernetes client config: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating Kubernetes client: %w", err)
+	}
+
+	holder := os.Getenv(PodNameEnv)
+	if holder == "" {
+		if holder, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("getting hostname to identify replica: %w", err)
+		}
+	}
+
+	storage, err := cache.NewConfigMapStorage(ctx, cache.ConfigMapStorageOptions{
+		Client:      clientset,
+		Namespace:   os.Getenv(PodNamespaceEnv),
+		Name:        options.ConfigMapName,
+		Holder:      holder,
+		LockTTL:     time.Duration(options.LockTTLSeconds) * time.Second,
+		MaxEntryAge: time.Duration(options.MaxEntryAgeSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating ConfigMap storage: %w", err)
 	}
 
-	return cacheProvider, nil
+	return storage, nil
 }
 
 func main() {
 	logs.InitLogs()
 	defer logs.FlushLogs()
 
-	klog.Infof("Starting NewRelic metrics adapter")
-
 	if err := Run(signals.SetupSignalHandler(), os.Args); err != nil {
-		klog.Fatalf("Running adapter failed: %v", err)
+		klog.ErrorS(err, "Running adapter failed")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
 }
 
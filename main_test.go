@@ -143,6 +143,96 @@ func Test_Run_fails_when(t *testing.T) {
 		}
 	})
 
+	t.Run("unsupported_logging_format_is_given", func(t *testing.T) {
+		t.Parallel()
+
+		flags := []string{"--cert-dir=" + t.TempDir(), "--logging-format=xml"}
+
+		err := adapter.Run(testContext(t), flags)
+		if err == nil {
+			t.Fatalf("Expected error running adapter")
+		}
+
+		expectedError := "unsupported logging format"
+
+		if !strings.Contains(err.Error(), expectedError) {
+			t.Fatalf("Expected error to contain %q, got %q", expectedError, err.Error())
+		}
+	})
+
+	t.Run("invalid_tracing_configuration_is_configured", func(t *testing.T) {
+		t.Parallel()
+
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		config := "accountID: 1\ntracing:\n  samplingRatePerMillion: 2000000\n"
+
+		if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+			t.Fatalf("Error writing test config file: %v", err)
+		}
+
+		err := adapter.Run(testContext(t), []string{"--cert-dir=" + t.TempDir(), "--config-file=" + configPath})
+		if err == nil {
+			t.Fatalf("Expected error running adapter")
+		}
+
+		expectedError := "validating tracing configuration"
+
+		if !strings.Contains(err.Error(), expectedError) {
+			t.Fatalf("Expected error to contain %q, got %q", expectedError, err.Error())
+		}
+	})
+
+	//nolint:paralleltest // We manipulate environment variables here which are global.
+	t.Run("metric_timeout_exceeds_NRDB_client_timeout", func(t *testing.T) {
+		setenv(t, adapter.NewRelicAPIKeyEnv, "foo")
+		setenv(t, adapter.ClusterNameEnv, "bar")
+		withoutGlobalMetricsRegistry(t)
+
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		config := "accountID: 1\nnrdbClientTimeoutSeconds: 10\nexternalMetrics:\n  foo:\n" +
+			"    query: select 1 from bar\n    timeoutSeconds: 20\n"
+
+		if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+			t.Fatalf("Error writing test config file: %v", err)
+		}
+
+		err := adapter.Run(testContext(t), []string{"--cert-dir=" + t.TempDir(), "--config-file=" + configPath})
+		if err == nil {
+			t.Fatalf("Expected error running adapter")
+		}
+
+		expectedError := "exceeds maximum of 10s"
+
+		if !strings.Contains(err.Error(), expectedError) {
+			t.Fatalf("Expected error to contain %q, got %q", expectedError, err.Error())
+		}
+	})
+
+	//nolint:paralleltest // We manipulate environment variables here which are global.
+	t.Run("unsupported_cache_storage_type_is_configured", func(t *testing.T) {
+		setenv(t, adapter.NewRelicAPIKeyEnv, "foo")
+		setenv(t, adapter.ClusterNameEnv, "bar")
+		withoutGlobalMetricsRegistry(t)
+
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		config := "accountID: 1\ncacheTTLSeconds: 10\ncacheStorage:\n  type: foo\n"
+
+		if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+			t.Fatalf("Error writing test config file: %v", err)
+		}
+
+		err := adapter.Run(testContext(t), []string{"--cert-dir=" + t.TempDir(), "--config-file=" + configPath})
+		if err == nil {
+			t.Fatalf("Expected error running adapter")
+		}
+
+		expectedError := "unsupported cache storage type"
+
+		if !strings.Contains(err.Error(), expectedError) {
+			t.Fatalf("Expected error to contain %q, got %q", expectedError, err.Error())
+		}
+	})
+
 	//nolint:paralleltest // We manipulate environment variables here which are global.
 	t.Run("initializing_cache_provider_fails", func(t *testing.T) {
 		setenv(t, adapter.NewRelicAPIKeyEnv, "foo")